@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/frankie-seb/sinatra/internal"
+	"github.com/urfave/cli/v2"
+)
+
+var checkCmd = &cli.Command{
+	Name:  "check",
+	Usage: "Check that the generated files are up to date, exits non-zero on drift",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "show logs"},
+		configFlag,
+		profileFlag,
+		&cli.BoolFlag{Name: "skip-db", Aliases: []string{"sdb"}, Usage: "skip the db models generation"},
+		ddlFlag,
+		&cli.BoolFlag{Name: "diff", Usage: "print a diff of the out of date files"},
+	},
+	Action: func(ctx *cli.Context) error {
+		cfg, err := loadConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		scratch, cleanup, err := generateInScratch(cfg, !ctx.Bool("skip-db"))
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		diffs, err := internal.DiffGeneratedFiles(".", scratch, cfg)
+		if err != nil {
+			return err
+		}
+
+		if len(diffs) == 0 {
+			fmt.Fprintln(stdout, "Generated files are up to date")
+			return nil
+		}
+
+		fmt.Fprintln(stdout, "Generated files are out of date, rerun sinatra generate:")
+		for _, d := range diffs {
+			fmt.Fprintln(stdout, "  "+d.Name)
+		}
+		if ctx.Bool("diff") {
+			for _, d := range diffs {
+				fmt.Fprint(stdout, d.Diff)
+			}
+		}
+		return cli.Exit(fmt.Sprintf("%d generated file(s) differ from the committed ones", len(diffs)), 1)
+	},
+}
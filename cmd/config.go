@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/frankie-seb/sinatra/internal"
+	"github.com/urfave/cli/v2"
+)
+
+var configCmd = &cli.Command{
+	Name:  "config",
+	Usage: "Validate the config or print its JSON Schema",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "validate",
+			Usage: "Check the config for unknown keys, wrong types, missing directories and contradictions",
+			Flags: []cli.Flag{
+				configFlag,
+				profileFlag,
+			},
+			Action: func(ctx *cli.Context) error {
+				filename := ctx.String("config")
+				if filename == "" {
+					var err error
+					if filename, err = internal.FindConfigFile(); err != nil {
+						return cli.Exit("no sinatra.yml found, run sinatra init to create one", 1)
+					}
+				}
+
+				problems, err := internal.ValidateConfigFile(filename, ctx.String("profile"))
+				if err != nil {
+					return err
+				}
+				if len(problems) == 0 {
+					fmt.Fprintf(stdout, "%s is valid\n", filename)
+					return nil
+				}
+
+				fmt.Fprintf(stdout, "%s is invalid:\n", filename)
+				for _, p := range problems {
+					fmt.Fprintln(stdout, "  "+p.String())
+				}
+				return cli.Exit(fmt.Sprintf("%d problem(s) found", len(problems)), 1)
+			},
+		},
+		{
+			Name:  "schema",
+			Usage: "Print the JSON Schema of the config, e.g. for editor autocompletion",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write the schema to this file instead of stdout"},
+			},
+			Action: func(ctx *cli.Context) error {
+				b, err := json.MarshalIndent(internal.ConfigJSONSchema(), "", "  ")
+				if err != nil {
+					return err
+				}
+				b = append(b, '\n')
+
+				if output := ctx.String("output"); output != "" {
+					return ioutil.WriteFile(output, b, 0644)
+				}
+				_, err = stdout.Write(b)
+				return err
+			},
+		},
+	},
+}
@@ -1,16 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 
-	"github.com/99designs/gqlgen/api"
-	gqlcon "github.com/99designs/gqlgen/codegen/config"
+	"github.com/frankie-seb/sinatra"
 	"github.com/frankie-seb/sinatra/internal"
-	"github.com/frankie-seb/sinatra/plugins/helpers"
-	"github.com/frankie-seb/sinatra/plugins/resolvers"
-	"github.com/frankie-seb/sinatra/plugins/schema"
-	"github.com/frankie-seb/sinatra/plugins/sqlboiler"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 )
@@ -20,69 +18,162 @@ var (
 		Name:  "generate",
 		Usage: "Generate the Sinatra ORM",
 		Flags: []cli.Flag{
-			&cli.BoolFlag{Name: "verbose, v", Usage: "show logs"},
-			&cli.StringFlag{Name: "config, c", Usage: "the config filename"},
-			&cli.StringFlag{Name: "skip-db, sdb", Usage: "where to write the server stub to"},
+			&cli.BoolFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "show logs"},
+			configFlag,
+			profileFlag,
+			&cli.BoolFlag{Name: "skip-db", Aliases: []string{"sdb"}, Usage: "skip the db models generation"},
+			ddlFlag,
+			&cli.BoolFlag{Name: "dry-run", Aliases: []string{"diff"}, Usage: "print a diff of the generated files without writing them"},
+			&cli.BoolFlag{Name: "json", Usage: "print the result and the errors as json, e.g. for annotations in ci"},
 		},
 		Action: func(ctx *cli.Context) error {
-			var cfg *internal.Config
-			var gqlcfg *gqlcon.Config
-			var err error
-			if configFilename := ctx.String("config"); configFilename != "" {
-				cfg, err = internal.LoadConfig(configFilename)
+			err := func() error {
+				cfg, err := loadConfig(ctx)
 				if err != nil {
 					return err
 				}
-			} else {
-				cfg, err = internal.LoadConfigFromDefaultLocations()
-				if os.IsNotExist(errors.Cause(err)) {
-					cfg, err = internal.LoadDefaultConfig()
-				}
 
-				if err != nil {
-					return err
+				if ctx.Bool("dry-run") {
+					return generateDryRun(cfg, !ctx.Bool("skip-db"))
 				}
+
+				return generate(cfg, !ctx.Bool("skip-db"))
+			}()
+			if ctx.Bool("json") {
+				return printJSONResult(err)
 			}
+			return err
+		},
+	}
+)
 
-			// Run db models generation
-			if skipDB := ctx.String("skip-db"); skipDB == "" {
-				if err = sqlboiler.Run(cfg); err != nil {
-					return err
+// loadConfig loads the config given by the config flag or looks it up in the default locations
+func loadConfig(ctx *cli.Context) (*internal.Config, error) {
+	var cfg *internal.Config
+	var err error
+	if configFilename := ctx.String("config"); configFilename != "" {
+		cfg, err = internal.LoadConfig(configFilename, ctx.String("profile"))
+	} else {
+		cfg, err = internal.LoadConfigFromDefaultLocations(ctx.String("profile"))
+		if os.IsNotExist(errors.Cause(err)) {
+			cfg, err = internal.LoadDefaultConfig()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
 
-				}
-			}
+	if ddl := ctx.String("ddl"); ddl != "" {
+		cfg.Database.DDL = ddl
+	}
+	return cfg, nil
+}
 
-			// Generate the schema
-			h := &schema.HooksConfig{}
-			if err := schema.SchemaWrite(cfg, h); err != nil {
-				fmt.Println("error while trying to generate schema")
-				fmt.Fprintln(os.Stderr, err.Error())
-				os.Exit(3)
-			}
+// generate runs the full generation pipeline in the current directory, a failing stage is
+// returned as *sinatra.StageError
+func generate(cfg *internal.Config, withDB bool) error {
+	stages := sinatra.StageSchema | sinatra.StageGraph
+	// Run db models generation
+	if withDB {
+		stages |= sinatra.StageModels
+	}
+	return runStages(cfg, stages)
+}
 
-			// Generate the gqlgen config
-			gqlcfg, err = internal.LoadGqlgenConfig(cfg)
-			if err != nil {
-				fmt.Println("error while trying to generate the config")
-				fmt.Fprintln(os.Stderr, err.Error())
-				os.Exit(3)
-			}
+// generateResult is printed by generate --json
+type generateResult struct {
+	Success  bool                     `json:"success"`
+	ExitCode int                      `json:"exitCode"`
+	Errors   []*sinatra.GenerateError `json:"errors"`
+}
 
-			// Run generator
-			if err = api.Generate(gqlcfg,
-				api.AddPlugin(helpers.NewHelperPlugin(
-					cfg,
-				)),
-				api.AddPlugin(resolvers.NewResolverPlugin(
-					cfg,
-				)),
-			); err != nil {
-				fmt.Println("error while trying run sinatra")
-				fmt.Fprintln(os.Stderr, err.Error())
-				os.Exit(3)
-			}
+// printJSONResult prints the outcome of the generate run as json and exits with its code
+func printJSONResult(err error) error {
+	result := generateResult{Success: err == nil, Errors: []*sinatra.GenerateError{}}
+	if err != nil {
+		result.ExitCode = exitCode(err)
+		var stageErr *sinatra.StageError
+		if errors.As(err, &stageErr) {
+			result.Errors = stageErr.Errors
+		} else {
+			result.Errors = append(result.Errors, &sinatra.GenerateError{Message: err.Error()})
+		}
+	}
 
-			return nil
-		},
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(result); encErr != nil {
+		return encErr
 	}
-)
+	if err != nil {
+		// the errors are in the json already
+		return cli.Exit("", result.ExitCode)
+	}
+	return nil
+}
+
+// runStages runs the given stages of the pipeline in order and stops at the first error
+func runStages(cfg *internal.Config, stages sinatra.Stage) error {
+	return sinatra.Generate(context.Background(), cfg, sinatra.WithStages(stages))
+}
+
+// generateInScratch copies the project to a temporary directory and runs the pipeline in
+// there, the project itself is left untouched. gqlgen type checks the generated packages
+// so they have to exist on disk, that's why we can not keep everything in memory.
+// The returned cleanup function removes the scratch directory.
+func generateInScratch(cfg *internal.Config, withDB bool) (string, func(), error) {
+	scratch, err := ioutil.TempDir("", "sinatra-")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to create scratch dir")
+	}
+	cleanup := func() {
+		os.RemoveAll(scratch)
+	}
+
+	if err := internal.CopyProject(".", scratch); err != nil {
+		cleanup()
+		return "", nil, errors.Wrap(err, "unable to copy project to scratch dir")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		cleanup()
+		return "", nil, errors.Wrap(err, "unable to get working dir")
+	}
+	if err := os.Chdir(scratch); err != nil {
+		cleanup()
+		return "", nil, errors.Wrap(err, "unable to enter scratch dir")
+	}
+	defer os.Chdir(wd) //nolint:errcheck
+
+	if err := generate(cfg, withDB); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return scratch, cleanup, nil
+}
+
+// generateDryRun renders everything in a scratch copy of the project and prints a unified
+// diff against the generated files on disk
+func generateDryRun(cfg *internal.Config, withDB bool) error {
+	scratch, cleanup, err := generateInScratch(cfg, withDB)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	diffs, err := internal.DiffGeneratedFiles(".", scratch, cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(diffs) == 0 {
+		fmt.Fprintln(stdout, "No changes, generated files are up to date")
+		return nil
+	}
+	for _, d := range diffs {
+		fmt.Fprint(stdout, d.Diff)
+	}
+	fmt.Fprintf(stdout, "\n%d generated file(s) would change\n", len(diffs))
+	return nil
+}
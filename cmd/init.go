@@ -3,12 +3,16 @@ package cmd
 import (
 	"bytes"
 	"fmt"
-	"html/template"
+	"go/format"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"text/template"
 
 	"github.com/frankie-seb/sinatra/internal"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 )
 
@@ -18,17 +22,17 @@ var (
 		Aliases: []string{"initialize", "initialise", "create"},
 		Usage:   "Initialize a Sinatra Project",
 		Flags: []cli.Flag{
-			&cli.BoolFlag{Name: "verbose, v", Usage: "show logs"},
-			&cli.StringFlag{Name: "config, c", Usage: "the config filename"},
+			&cli.BoolFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "show logs"},
+			configFlag,
 			&cli.StringFlag{Name: "server", Usage: "where to write the server stub to", Value: "server.go"},
-			&cli.StringFlag{Name: "schema", Usage: "where to write the schema stub to", Value: "graph/schema.graphqls"},
+			&cli.StringFlag{Name: "schema", Usage: "the schema directory of a newly written config", Value: "schema"},
 		},
 		Action: func(ctx *cli.Context) error {
 			err := initializeProject(ctx)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Your Sinatra ORM is ready")
+			fmt.Fprintln(stdout, "Your Sinatra ORM is ready, run go mod tidy and sinatra generate")
 			return nil
 		},
 	}
@@ -43,26 +47,38 @@ func initializeProject(ctx *cli.Context) error {
 	}
 
 	if !configExists(configFilename) {
-		if err := initConfig(configFilename, pkgName); err != nil {
+		if err := initConfig(configFilename, ctx.String("schema")); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	var cfg *internal.Config
+	var err error
+	if configFilename != "" {
+		cfg, err = internal.LoadConfig(configFilename, "")
+	} else {
+		cfg, err = internal.LoadConfigFromDefaultLocations("")
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to load config")
+	}
+
+	return scaffold(cfg, pkgName, ctx.String("server"))
 }
 
 func configExists(configFilename string) bool {
-	var cfg *internal.Config
+	var err error
 
 	if configFilename != "" {
-		cfg, _ = internal.LoadConfig(configFilename)
+		_, err = internal.LoadConfig(configFilename, "")
 	} else {
-		cfg, _ = internal.LoadConfigFromDefaultLocations()
+		_, err = internal.LoadConfigFromDefaultLocations("")
 	}
-	return cfg != nil
+	// a config which can't be loaded, e.g. because of a missing env var, still exists
+	return err == nil || !os.IsNotExist(errors.Cause(err))
 }
 
-func initConfig(configFilename string, pkgName string) error {
+func initConfig(configFilename string, schemaDir string) error {
 	if configFilename == "" {
 		configFilename = "sinatra.yml"
 	}
@@ -71,7 +87,7 @@ func initConfig(configFilename string, pkgName string) error {
 		return fmt.Errorf("unable to create config dir: " + err.Error())
 	}
 
-	c, err := internal.GetTemplateContent("config.gotpl")
+	c, err := internal.GetTemplateContent(nil, "config.gotpl")
 	if err != nil {
 		return fmt.Errorf("could not load template: %v", err)
 	}
@@ -82,8 +98,8 @@ func initConfig(configFilename string, pkgName string) error {
 	}
 
 	var buf bytes.Buffer
-	if err := tpl.Execute(&buf, pkgName); err != nil {
-		panic(err)
+	if err := tpl.Execute(&buf, struct{ SchemaDir string }{schemaDir}); err != nil {
+		return fmt.Errorf("execute: %v", err)
 	}
 
 	if err := ioutil.WriteFile(configFilename, buf.Bytes(), 0644); err != nil {
@@ -92,3 +108,158 @@ func initConfig(configFilename string, pkgName string) error {
 
 	return nil
 }
+
+// scaffoldData is what the server, dataloader and directive stubs are rendered with
+type scaffoldData struct {
+	Config           *internal.Config
+	Directives       []string
+	DriverName       string
+	DriverImport     string
+	GraphImport      string
+	ResolverImport   string
+	DataloaderImport string
+	// DenyDirectives guard the permanentlyDelete mutations, their stubs deny everyone until they are implemented
+	DenyDirectives []string
+}
+
+// IsDenyDirective reports whether the stub of the directive denies everyone
+func (d scaffoldData) IsDenyDirective(name string) bool {
+	return internal.SliceContains(d.DenyDirectives, name)
+}
+
+// HasAuthDirectives reports whether a stub lets the authenticated users through
+func (d scaffoldData) HasAuthDirectives() bool {
+	for _, name := range d.Directives {
+		if !d.IsDenyDirective(name) {
+			return true
+		}
+	}
+	return false
+}
+
+type scaffoldFile struct {
+	filename string
+	template string
+}
+
+var sqlDrivers = map[string]struct{ name, importPath string }{
+	internal.DriverPsql:   {"postgres", "github.com/lib/pq"},
+	internal.DriverMysql:  {"mysql", "github.com/go-sql-driver/mysql"},
+	internal.DriverSqlite: {"sqlite3", "github.com/mattn/go-sqlite3"},
+}
+
+// scaffold writes the stubs of a runnable service next to the config, existing files are left untouched
+func scaffold(cfg *internal.Config, pkgName string, serverFilename string) error {
+	driver, ok := sqlDrivers[cfg.Database.DBDriver]
+	if !ok {
+		driver = sqlDrivers[internal.DriverPsql]
+	}
+	// the directives guarding the permanentlyDelete mutations need stubs as well
+	directives := append(append([]string(nil), cfg.Schema.Directives...), cfg.Schema.PermanentDeleteDirectives...)
+	data := scaffoldData{
+		Config:           cfg,
+		Directives:       directiveNames(directives),
+		DenyDirectives:   directiveNames(cfg.Schema.PermanentDeleteDirectives),
+		DriverName:       driver.name,
+		DriverImport:     driver.importPath,
+		GraphImport:      path.Join(pkgName, cfg.Graph.DirName),
+		ResolverImport:   path.Join(pkgName, cfg.Resolver.DirName),
+		DataloaderImport: path.Join(pkgName, "dataloader"),
+	}
+
+	if err := os.MkdirAll(cfg.Schema.DirName, 0755); err != nil {
+		return errors.Wrap(err, "unable to create schema dir")
+	}
+
+	files := []scaffoldFile{
+		{serverFilename, "server.gotpl"},
+		{filepath.Join("dataloader", "dataloader.go"), "dataloader.gotpl"},
+		{filepath.Join(cfg.Resolver.DirName, "directives.go"), "directives.gotpl"},
+	}
+	// the generated schema uses the directives, gqlgen needs them to be declared
+	if len(data.Directives) > 0 {
+		files = append(files, scaffoldFile{filepath.Join(cfg.Schema.DirName, "directives.graphql"), "directives_schema.gotpl"})
+	}
+	for _, f := range files {
+		if internal.FileExists(f.filename) {
+			continue
+		}
+		if err := writeScaffoldFile(f.filename, f.template, data); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "Created "+f.filename)
+	}
+
+	return addMakefileTarget("Makefile")
+}
+
+// directiveNames strips the arguments of the directives
+func directiveNames(directives []string) []string {
+	names := make([]string, 0, len(directives))
+	for _, d := range directives {
+		name := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(d, "@"), "(", 2)[0])
+		if name != "" && !internal.SliceContains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func writeScaffoldFile(filename string, templateName string, data scaffoldData) error {
+	c, err := internal.GetTemplateContent(nil, templateName)
+	if err != nil {
+		return fmt.Errorf("could not load template: %v", err)
+	}
+
+	tpl, err := template.New("").Funcs(template.FuncMap{"go": internal.ToGo}).Parse(c)
+	if err != nil {
+		return fmt.Errorf("parse %v: %v", templateName, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("execute %v: %v", templateName, err)
+	}
+
+	content := buf.Bytes()
+	if strings.HasSuffix(filename, ".go") {
+		if content, err = format.Source(content); err != nil {
+			return errors.Wrapf(err, "unable to format %v", filename)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.Wrapf(err, "unable to create dir of %v", filename)
+	}
+	if err := ioutil.WriteFile(filename, content, 0644); err != nil {
+		return errors.Wrapf(err, "unable to write %v", filename)
+	}
+	return nil
+}
+
+// addMakefileTarget adds a generate target to the Makefile, it is created when missing
+func addMakefileTarget(filename string) error {
+	existing, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "unable to read Makefile")
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.HasPrefix(line, "generate:") {
+			return nil
+		}
+	}
+
+	target, err := internal.GetTemplateContent(nil, "makefile.gotpl")
+	if err != nil {
+		return fmt.Errorf("could not load template: %v", err)
+	}
+	content := string(existing)
+	if content != "" {
+		content = strings.TrimRight(content, "\n") + "\n\n"
+	}
+	if err := ioutil.WriteFile(filename, []byte(content+target), 0644); err != nil {
+		return errors.Wrap(err, "unable to write Makefile")
+	}
+	fmt.Fprintln(stdout, "Added the generate target to "+filename)
+	return nil
+}
@@ -2,19 +2,40 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/frankie-seb/sinatra"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
 )
 
+// stdout is where the commands print their reports, the banner and the logs go to stderr so the
+// output of e.g. generate --json can be piped
+var stdout io.Writer = os.Stdout
+
+// flags of the commands which load the config
+var (
+	configFlag  = &cli.StringFlag{Name: "config", Aliases: []string{"c"}, Usage: "the config filename"}
+	profileFlag = &cli.StringFlag{Name: "profile", Aliases: []string{"p"}, EnvVars: []string{"SINATRA_PROFILE"}, Usage: "the config profile layered over the base config"}
+	ddlFlag     = &cli.StringFlag{Name: "ddl", Usage: "read the tables from a sql schema file or migrations directory instead of the database"}
+)
+
+// exit codes of a failing stage, other errors exit with 1
+const (
+	exitModels = 2
+	exitSchema = 3
+	exitGraph  = 4
+)
+
 func init() { //nolint:gochecknoinits
-	fmt.Println("")
-	fmt.Println("   d888888o.    8 8888 b.             8          .8.    8888888 8888888888 8 888888888o.            .8.          \n .`8888:' `88.  8 8888 888o.          8         .888.         8 8888       8 8888    `88.          .888.         \n 8.`8888.   Y8  8 8888 Y88888o.       8        :88888.        8 8888       8 8888     `88         :88888.        \n `8.`8888.      8 8888 .`Y888888o.    8       . `88888.       8 8888       8 8888     ,88        . `88888.       \n  `8.`8888.     8 8888 8o. `Y888888o. 8      .8. `88888.      8 8888       8 8888.   ,88'       .8. `88888.      \n   `8.`8888.    8 8888 8`Y8o. `Y88888o8     .8`8. `88888.     8 8888       8 888888888P'       .8`8. `88888.     \n    `8.`8888.   8 8888 8   `Y8o. `Y8888    .8' `8. `88888.    8 8888       8 8888`8b          .8' `8. `88888.    \n8b   `8.`8888.  8 8888 8      `Y8o. `Y8   .8'   `8. `88888.   8 8888       8 8888 `8b.       .8'   `8. `88888.   \n`8b.  ;8.`8888  8 8888 8         `Y8o.`  .888888888. `88888.  8 8888       8 8888   `8b.    .888888888. `88888.  \n `Y8888P ,88P'  8 8888 8            `Yo .8'       `8. `88888. 8 8888       8 8888     `88. .8'       `8. `88888. ") //nolint:lll
-	fmt.Println("")
-	fmt.Println("♫ Fly me to the moon... ♫ Sinatra - a transparent ORM ♫")
-	fmt.Println("")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "   d888888o.    8 8888 b.             8          .8.    8888888 8888888888 8 888888888o.            .8.          \n .`8888:' `88.  8 8888 888o.          8         .888.         8 8888       8 8888    `88.          .888.         \n 8.`8888.   Y8  8 8888 Y88888o.       8        :88888.        8 8888       8 8888     `88         :88888.        \n `8.`8888.      8 8888 .`Y888888o.    8       . `88888.       8 8888       8 8888     ,88        . `88888.       \n  `8.`8888.     8 8888 8o. `Y888888o. 8      .8. `88888.      8 8888       8 8888.   ,88'       .8. `88888.      \n   `8.`8888.    8 8888 8`Y8o. `Y88888o8     .8`8. `88888.     8 8888       8 888888888P'       .8`8. `88888.     \n    `8.`8888.   8 8888 8   `Y8o. `Y8888    .8' `8. `88888.    8 8888       8 8888`8b          .8' `8. `88888.    \n8b   `8.`8888.  8 8888 8      `Y8o. `Y8   .8'   `8. `88888.   8 8888       8 8888 `8b.       .8'   `8. `88888.   \n`8b.  ;8.`8888  8 8888 8         `Y8o.`  .888888888. `88888.  8 8888       8 8888   `8b.    .888888888. `88888.  \n `Y8888P ,88P'  8 8888 8            `Yo .8'       `8. `88888. 8 8888       8 8888     `88. .8'       `8. `88888. ") //nolint:lll
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "♫ Fly me to the moon... ♫ Sinatra - a transparent ORM ♫")
+	fmt.Fprintln(os.Stderr, "")
 }
 
 // Execute executes the root command.
@@ -32,8 +53,6 @@ func Execute() {
 			log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 		} else {
 			zerolog.SetGlobalLevel(zerolog.Disabled)
-			os.Stdout = nil
-			os.Stderr = nil
 		}
 		return nil
 	}
@@ -41,12 +60,32 @@ func Execute() {
 	app.Action = generateCmd.Action
 	app.Commands = []*cli.Command{
 		generateCmd,
+		checkCmd,
+		watchCmd,
+		configCmd,
 		initCmd,
 		versionCmd,
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		fmt.Fprint(os.Stderr, err.Error())
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode returns the exit code of the failing stage, or 1 when the error is not from a stage
+func exitCode(err error) int {
+	var stageErr *sinatra.StageError
+	if !errors.As(err, &stageErr) {
+		return 1
+	}
+	switch stageErr.Stage {
+	case sinatra.StageModels:
+		return exitModels
+	case sinatra.StageSchema:
+		return exitSchema
+	case sinatra.StageGraph:
+		return exitGraph
 	}
+	return 1
 }
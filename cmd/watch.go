@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/frankie-seb/sinatra"
+	"github.com/frankie-seb/sinatra/internal"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// watchDebounce is how long the watcher waits for more changes before regenerating
+const watchDebounce = 500 * time.Millisecond
+
+var watchCmd = &cli.Command{
+	Name:  "watch",
+	Usage: "Watch schema extensions, overrides and migrations and regenerate on change",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "show logs"},
+		configFlag,
+		profileFlag,
+		&cli.BoolFlag{Name: "skip-db", Aliases: []string{"sdb"}, Usage: "skip the db models generation"},
+		ddlFlag,
+		&cli.StringFlag{Name: "migrations", Aliases: []string{"m"}, Usage: "the migrations directory to watch"},
+	},
+	Action: func(ctx *cli.Context) error {
+		cfg, err := loadConfig(ctx)
+		if err != nil {
+			return err
+		}
+		migrationsDir := ctx.String("migrations")
+		// generating from a migrations directory, watch it by default
+		if info, err := os.Stat(cfg.Database.DDL); migrationsDir == "" && err == nil && info.IsDir() {
+			migrationsDir = cfg.Database.DDL
+		}
+		return watch(cfg, migrationsDir, !ctx.Bool("skip-db"))
+	},
+}
+
+// watch regenerates the affected stages of the pipeline whenever a watched file changes,
+// it blocks until the watcher is closed
+func watch(cfg *internal.Config, migrationsDir string, withDB bool) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "unable to create watcher")
+	}
+	defer watcher.Close()
+
+	dirs := append([]string{cfg.Schema.DirName, cfg.Resolver.DirName, cfg.Helper.DirName}, cfg.Templates.Dirs...)
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		if err := watchTree(watcher, dir); err != nil {
+			return err
+		}
+	}
+	if migrationsDir != "" {
+		if err := watchTree(watcher, migrationsDir); err != nil {
+			return errors.Wrap(err, "unable to watch migrations")
+		}
+	}
+
+	fmt.Fprintln(stdout, "Watching for changes, press ctrl+c to stop")
+
+	var pending sinatra.Stage
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	// a regeneration runs in the background so changes made meanwhile are queued for the next run
+	running := false
+	done := make(chan error, 1)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				if running {
+					<-done
+				}
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			s := watchStages(cfg, migrationsDir, event.Name)
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					s |= watchNewDir(cfg, watcher, migrationsDir, event.Name)
+				}
+			}
+			if s != 0 {
+				pending |= s
+				if !running {
+					timer.Reset(watchDebounce)
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				if running {
+					<-done
+				}
+				return nil
+			}
+			fmt.Fprintln(stdout, "watch error: "+err.Error())
+		case <-timer.C:
+			stages := pending
+			pending = 0
+			if !withDB {
+				stages &^= sinatra.StageModels
+			}
+			if stages == 0 {
+				continue
+			}
+
+			fmt.Fprintf(stdout, "Regenerating %v\n", stages)
+			running = true
+			go func() { done <- runStages(cfg, stages) }()
+		case err := <-done:
+			running = false
+			if err != nil {
+				fmt.Fprintln(stdout, err.Error())
+			} else {
+				fmt.Fprintln(stdout, "Done")
+			}
+			if pending != 0 {
+				timer.Reset(watchDebounce)
+			}
+		}
+	}
+}
+
+// watchTree watches dir and all directories below it, fsnotify only reports changes of the direct
+// children of a watched directory
+func watchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrapf(err, "unable to watch %s", path)
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return errors.Wrapf(watcher.Add(path), "unable to watch %s", path)
+	})
+}
+
+// watchNewDir watches a directory created below a watched one and returns the stages of the files
+// which were written to it before the watch was added
+func watchNewDir(cfg *internal.Config, watcher *fsnotify.Watcher, migrationsDir string, dir string) sinatra.Stage {
+	if err := watchTree(watcher, dir); err != nil {
+		fmt.Fprintln(stdout, "watch error: "+err.Error())
+	}
+	var stages sinatra.Stage
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			stages |= watchStages(cfg, migrationsDir, path)
+		}
+		return nil
+	})
+	return stages
+}
+
+// watchStages returns the stages which have to rerun when the given file changed, files in
+// subdirectories count for the watched directory they are in
+func watchStages(cfg *internal.Config, migrationsDir string, name string) sinatra.Stage {
+	name = filepath.Clean(name)
+
+	switch {
+	case migrationsDir != "" && inDir(name, migrationsDir):
+		// the database changed so everything has to be regenerated
+		return sinatra.StagesAll
+	case internal.IsGeneratedFile(cfg, name):
+		return 0
+	case isInTemplateDir(cfg, name) && strings.HasSuffix(name, ".gotpl"):
+		return sinatra.StageGraph
+	case inDir(name, cfg.Schema.DirName) && strings.HasSuffix(name, ".graphql"):
+		return sinatra.StageGraph
+	case (inDir(name, cfg.Resolver.DirName) || inDir(name, cfg.Helper.DirName)) &&
+		strings.HasSuffix(name, ".go"):
+		return sinatra.StageGraph
+	}
+	return 0
+}
+
+func isInTemplateDir(cfg *internal.Config, name string) bool {
+	for _, d := range cfg.Templates.Dirs {
+		if inDir(name, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// inDir reports whether name is below dir, at any depth
+func inDir(name, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), name)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,142 @@
+package helpers
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/volatiletech/null/v8"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// Aggregate selects the count and the aggregates of the columns, grouped by the GroupBy columns.
+// All columns are qualified with their table e.g. dm.UserTableColumns.Age.
+type Aggregate struct {
+	GroupBy []string
+	// Numbers are summed and averaged and have a min and max
+	Numbers []string
+	// Times have a min and max
+	Times []string
+}
+
+// AggregateRow is a row of an aggregate query, the values are in the order of the columns of the
+// Aggregate
+type AggregateRow struct {
+	Count   int
+	Groups  []null.String
+	Sum     []null.Float64
+	Avg     []null.Float64
+	Min     []null.Float64
+	Max     []null.Float64
+	MinTime []null.Time
+	MaxTime []null.Time
+}
+
+// Mods returns the select and group by mods of the aggregate
+func (a Aggregate) Mods() []qm.QueryMod {
+	columns := append([]string{}, a.GroupBy...)
+	columns = append(columns, "COUNT(*)")
+	for _, f := range []string{"SUM", "AVG", "MIN", "MAX"} {
+		for _, c := range a.Numbers {
+			columns = append(columns, fmt.Sprintf("%s(%s)", f, c))
+		}
+	}
+	for _, f := range []string{"MIN", "MAX"} {
+		for _, c := range a.Times {
+			columns = append(columns, fmt.Sprintf("%s(%s)", f, c))
+		}
+	}
+
+	mods := []qm.QueryMod{qm.Select(columns...)}
+	if len(a.GroupBy) > 0 {
+		groupBy := strings.Join(a.GroupBy, ", ")
+		mods = append(mods, qm.GroupBy(groupBy), qm.OrderBy(groupBy))
+	}
+	return mods
+}
+
+// Scan reads the rows of the query made with the Mods and closes them
+func (a Aggregate) Scan(rows *sql.Rows) ([]*AggregateRow, error) {
+	defer rows.Close()
+
+	var result []*AggregateRow
+	for rows.Next() {
+		r := &AggregateRow{
+			Groups:  make([]null.String, len(a.GroupBy)),
+			Sum:     make([]null.Float64, len(a.Numbers)),
+			Avg:     make([]null.Float64, len(a.Numbers)),
+			Min:     make([]null.Float64, len(a.Numbers)),
+			Max:     make([]null.Float64, len(a.Numbers)),
+			MinTime: make([]null.Time, len(a.Times)),
+			MaxTime: make([]null.Time, len(a.Times)),
+		}
+		// times are scanned as is since not every driver returns them as time.Time
+		minTimes := make([]interface{}, len(a.Times))
+		maxTimes := make([]interface{}, len(a.Times))
+
+		var dest []interface{}
+		for i := range r.Groups {
+			dest = append(dest, &r.Groups[i])
+		}
+		dest = append(dest, &r.Count)
+		for _, values := range [][]null.Float64{r.Sum, r.Avg, r.Min, r.Max} {
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+		}
+		for _, values := range [][]interface{}{minTimes, maxTimes} {
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("could not scan aggregate: %w", err)
+		}
+		for i := range a.Times {
+			var err error
+			if r.MinTime[i], err = toNullTime(minTimes[i]); err != nil {
+				return nil, err
+			}
+			if r.MaxTime[i], err = toNullTime(maxTimes[i]); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read aggregate: %w", err)
+	}
+	return result, nil
+}
+
+// timeLayouts are the layouts in which sqlite and mysql without parseTime return times
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func toNullTime(v interface{}) (null.Time, error) {
+	var s string
+	switch t := v.(type) {
+	case nil:
+		return null.Time{}, nil
+	case time.Time:
+		return null.TimeFrom(t), nil
+	case string:
+		s = t
+	case []byte:
+		s = string(t)
+	default:
+		return null.Time{}, fmt.Errorf("could not convert %T to time", v)
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return null.TimeFrom(t), nil
+		}
+	}
+	return null.Time{}, fmt.Errorf("could not parse time %q", s)
+}
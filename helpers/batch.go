@@ -1,6 +1,14 @@
-package base_helpers
+package helpers
 
-import "strings"
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
 
 func GetQuestionMarksForColumns(columns []string) string {
 	b := new(strings.Builder)
@@ -14,3 +22,152 @@ func GetQuestionMarksForColumns(columns []string) string {
 	b.WriteString(")")
 	return b.String()
 }
+
+// BatchInsertDialect are the differences between the databases for a multi-row insert
+type BatchInsertDialect struct {
+	// Quote quotes the identifiers e.g. " or `
+	Quote string
+	// NumberedMarks uses $1, $2 instead of ? as placeholders
+	NumberedMarks bool
+	// MaxParameters is the maximum number of values in a query
+	MaxParameters int
+}
+
+// Query returns the multi-row insert of the rows into the columns of the table which returns the
+// returning column of the inserted rows
+func (d BatchInsertDialect) Query(table string, columns []string, rows int, returning string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = d.Quote + c + d.Quote
+	}
+
+	b := new(strings.Builder)
+	b.WriteString("INSERT INTO " + d.Quote + table + d.Quote)
+	// rows without columns are inserted one by one
+	if len(columns) == 0 {
+		b.WriteString(" DEFAULT VALUES")
+		rows = 0
+	} else {
+		b.WriteString(" (" + strings.Join(quoted, ", ") + ") VALUES ")
+	}
+	n := 0
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			b.WriteString(", ")
+		}
+		if !d.NumberedMarks {
+			b.WriteString(GetQuestionMarksForColumns(columns))
+			continue
+		}
+		b.WriteString("(")
+		for i := range columns {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+		}
+		b.WriteString(")")
+	}
+	if returning != "" {
+		b.WriteString(" RETURNING " + d.Quote + returning + d.Quote)
+	}
+	return b.String()
+}
+
+// BatchCreateGroup are the rows of a batch create which set the same columns, they are inserted
+// together so the columns which are not set keep their database default
+type BatchCreateGroup struct {
+	Columns []string
+	// Rows are the indexes of the rows in the batch, in the order of the batch
+	Rows []int
+}
+
+// GroupBatchCreateRows groups the rows of a batch create by the columns they set
+func GroupBatchCreateRows(columnsPerRow [][]string) []*BatchCreateGroup {
+	var groups []*BatchCreateGroup
+	byColumns := map[string]*BatchCreateGroup{}
+	for i, columns := range columnsPerRow {
+		columns = uniqueSortedColumns(columns)
+		key := strings.Join(columns, ",")
+		g, ok := byColumns[key]
+		if !ok {
+			g = &BatchCreateGroup{Columns: columns}
+			byColumns[key] = g
+			groups = append(groups, g)
+		}
+		g.Rows = append(g.Rows, i)
+	}
+	return groups
+}
+
+// Chunks splits the rows of the group so a chunk has at most maxParameters values
+func (g *BatchCreateGroup) Chunks(maxParameters int) [][]int {
+	// rows without columns can't be inserted together
+	size := 1
+	if len(g.Columns) > 0 {
+		size = len(g.Rows)
+		if maxParameters > 0 {
+			size = maxParameters / len(g.Columns)
+		}
+	}
+	if size < 1 {
+		size = 1
+	}
+	var chunks [][]int
+	for start := 0; start < len(g.Rows); start += size {
+		end := start + size
+		if end > len(g.Rows) {
+			end = len(g.Rows)
+		}
+		chunks = append(chunks, g.Rows[start:end])
+	}
+	return chunks
+}
+
+// BatchInsertScanIDs runs the multi-row insert and scans the returned ids, id returns the destination
+// of the id of the row
+func BatchInsertScanIDs(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	query string,
+	values []interface{},
+	id func(row int) interface{},
+) error {
+	rows, err := exec.QueryContext(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for row := 0; rows.Next(); row++ {
+		if err := rows.Scan(id(row)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ChangedColumns returns the columns of which before and after return different values, the insert hooks
+// may set columns which are not part of the input and sqlboiler inserts those as well
+func ChangedColumns(columns []string, before, after func(column string) interface{}) []string {
+	var changed []string
+	for _, column := range columns {
+		if !reflect.DeepEqual(before(column), after(column)) {
+			changed = append(changed, column)
+		}
+	}
+	return changed
+}
+
+func uniqueSortedColumns(columns []string) []string {
+	seen := map[string]bool{}
+	var a []string
+	for _, c := range columns {
+		if !seen[c] {
+			seen[c] = true
+			a = append(a, c)
+		}
+	}
+	sort.Strings(a)
+	return a
+}
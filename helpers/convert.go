@@ -15,8 +15,29 @@ import (
 	"github.com/volatiletech/sqlboiler/v4/types"
 )
 
+// IDSeparator separates the type from the primary key in a global id, e.g. userRoles-12
 const IDSeparator = "-"
 
+// GlobalIDType returns the type of the global ids of the table, the lower camel cased table name
+func GlobalIDType(tableName string) string {
+	return strcase.ToLowerCamel(tableName)
+}
+
+// GlobalID returns the global id of the primary key of the table
+func GlobalID(tableName string, key string) string {
+	return GlobalIDType(tableName) + IDSeparator + key
+}
+
+// ParseGlobalID splits a global id into its type and primary key, the key is everything after the
+// first separator so string primary keys may contain it
+func ParseGlobalID(id string) (string, string, error) {
+	splitID := strings.SplitN(id, IDSeparator, 2)
+	if len(splitID) != 2 || splitID[0] == "" || splitID[1] == "" {
+		return "", "", fmt.Errorf("invalid id %q", id)
+	}
+	return splitID[0], splitID[1], nil
+}
+
 type RemovedID struct {
 	ID uint
 }
@@ -114,11 +135,8 @@ func StringIDsToBoilerString(ids []string) []string {
 }
 
 func StringIDToBoilerString(id string) string {
-	splitID := strings.SplitN(id, IDSeparator, 2)
-	if len(splitID) == 2 {
-		return splitID[1]
-	}
-	return ""
+	_, key, _ := ParseGlobalID(id) //nolint:errcheck
+	return key
 }
 
 func IDsToBoiler(ids []string) []uint {
@@ -130,13 +148,13 @@ func IDsToBoiler(ids []string) []uint {
 }
 
 func IDToBoiler(id string) uint {
-	splitID := strings.SplitN(id, IDSeparator, 2)
-	if len(splitID) == 2 {
-		// nolint: errcheck
-		i, _ := strconv.ParseUint(splitID[1], 10, 64)
-		return uint(i)
+	_, key, err := ParseGlobalID(id)
+	if err != nil {
+		return 0
 	}
-	return 0
+	// nolint: errcheck
+	i, _ := strconv.ParseUint(key, 10, 64)
+	return uint(i)
 }
 
 func IDsToBoilerUint(ids []string) []uint {
@@ -169,6 +187,28 @@ func IDsToBoilerInt(ids []string) []int {
 	return ints
 }
 
+func IDToBoilerInt64(id string) int64 {
+	return int64(IDToBoiler(id))
+}
+
+func IDToBoilerNullInt64(id *string) null.Int64 {
+	if id == nil {
+		return null.Int64{
+			Int64: 0,
+			Valid: false,
+		}
+	}
+	return null.Int64From(int64(IDToBoiler(*id)))
+}
+
+func IDsToBoilerInt64(ids []string) []int64 {
+	ints := make([]int64, len(ids))
+	for index, stringID := range ids {
+		ints[index] = IDToBoilerInt64(stringID)
+	}
+	return ints
+}
+
 func IDToNullBoiler(id string) null.Uint {
 	uintID := IDToBoiler(id)
 	if uintID == 0 {
@@ -188,16 +228,16 @@ func NullUintToNullInt(u null.Uint) null.Int {
 }
 
 func IDToGraphQL(id uint, tableName string) string {
-	return strcase.ToLowerCamel(tableName) + IDSeparator + strconv.Itoa(int(id))
+	return GlobalID(tableName, strconv.FormatUint(uint64(id), 10))
 }
 
 func IDToGraphQLPointer(id uint, tableName string) *string {
-	str := strcase.ToLowerCamel(tableName) + IDSeparator + strconv.Itoa(int(id))
+	str := IDToGraphQL(id, tableName)
 	return &str
 }
 
 func StringIDToGraphQL(id string, tableName string) string {
-	return strcase.ToLowerCamel(tableName) + IDSeparator + id
+	return GlobalID(tableName, id)
 }
 
 func StringIDsToGraphQL(ids []string, tableName string) []string {
@@ -232,6 +272,18 @@ func IntIDsToGraphQL(ids []int, tableName string) []string {
 	return stringIDs
 }
 
+func Int64IDToGraphQL(id int64, tableName string) string {
+	return IDToGraphQL(uint(id), tableName)
+}
+
+func Int64IDsToGraphQL(ids []int64, tableName string) []string {
+	stringIDs := make([]string, len(ids))
+	for index, id := range ids {
+		stringIDs[index] = Int64IDToGraphQL(id, tableName)
+	}
+	return stringIDs
+}
+
 func NullDotBoolToPointerBool(v null.Bool) *bool {
 	return v.Ptr()
 }
@@ -584,6 +636,21 @@ func NullDotIntToPointerInt(v null.Int) *int {
 	return v.Ptr()
 }
 
+func PointerIntToNullDotInt64(v *int) null.Int64 {
+	if v == nil {
+		return null.Int64FromPtr(nil)
+	}
+	return null.Int64From(int64(*v))
+}
+
+func NullDotInt64ToPointerInt(v null.Int64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
+
 func IntToInt8(v int) int8 {
 	return int8(v)
 }
@@ -624,6 +691,26 @@ func PointerIntToInt16(v *int) int16 {
 	return 0
 }
 
+func Int64ToInt(v int64) int {
+	return int(v)
+}
+
+func IntToInt64(v int) int64 {
+	return int64(v)
+}
+
+func PointerIntToInt64(v *int) int64 {
+	if v != nil {
+		return int64(*v)
+	}
+
+	return 0
+}
+
+func Int64ToUint(v int64) uint {
+	return uint(v)
+}
+
 func BoolToInt(v bool) int {
 	if v {
 		return 1
@@ -668,6 +755,10 @@ func NullDotUintToUint(v null.Uint) uint {
 	return v.Uint
 }
 
+func NullDotInt64ToUint(v null.Int64) uint {
+	return uint(v.Int64)
+}
+
 func NullDotIntIsFilled(v null.Int) bool {
 	return !v.IsZero()
 }
@@ -676,6 +767,10 @@ func NullDotUintIsFilled(v null.Uint) bool {
 	return !v.IsZero()
 }
 
+func NullDotInt64IsFilled(v null.Int64) bool {
+	return !v.IsZero()
+}
+
 func UintIsFilled(v uint) bool {
 	return v != 0
 }
@@ -684,6 +779,10 @@ func IntIsFilled(v int) bool {
 	return v != 0
 }
 
+func Int64IsFilled(v int64) bool {
+	return v != 0
+}
+
 func NullDotStringIsFilled(v null.String) bool {
 	return !v.IsZero()
 }
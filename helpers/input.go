@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/volatiletech/sqlboiler/v4/boil"
 )
 
 // Export input from the current context
@@ -38,3 +39,50 @@ func GetInputFromContext(ctx context.Context, key string) map[string]interface{}
 
 	return variables
 }
+
+// GetInputListFromContext returns the objects of the list field of the input with the keys which are
+// set in each of them, e.g. the posts of the input of createPosts, or of the input itself when field
+// is empty. Inline values and variables are both resolved.
+func GetInputListFromContext(ctx context.Context, key string, field string) []map[string]interface{} {
+	for _, arg := range graphql.GetFieldContext(ctx).Field.Arguments {
+		if arg.Name != key {
+			continue
+		}
+		input, err := arg.Value.Value(graphql.GetOperationContext(ctx).Variables)
+		if err != nil {
+			return nil
+		}
+		if field != "" {
+			fields, _ := input.(map[string]interface{})
+			input = fields[field]
+		}
+		// list input coercion accepts a single object where a list is expected
+		list, ok := input.([]interface{})
+		if item, isMap := input.(map[string]interface{}); !ok && isMap {
+			list = []interface{}{item}
+		}
+		a := make([]map[string]interface{}, len(list))
+		for i, item := range list {
+			a[i], _ = item.(map[string]interface{})
+		}
+		return a
+	}
+	return nil
+}
+
+// UpsertUpdateColumns returns the columns to update when an upsert conflicts, the columns set in the
+// input and the conflict columns. The conflict columns are updated to their own value so the upsert
+// also returns the row when nothing else is given, postgres returns nothing when nothing is updated.
+func UpsertUpdateColumns(set boil.Columns, conflictColumns []string) boil.Columns {
+	columns := append([]string{}, set.Cols...)
+	for _, c := range conflictColumns {
+		found := false
+		for _, s := range columns {
+			found = found || s == c
+		}
+		if !found {
+			columns = append(columns, c)
+		}
+	}
+	return boil.Whitelist(columns...)
+}
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -235,6 +236,40 @@ func GetCursorWhere(comparisonSign ComparisonSign, columns []string, values []in
 		parenthese(strings.TrimSuffix(strings.Repeat("?,", len(values)), ","))
 }
 
+// GetCursorWhereExpanded is the same comparison as GetCursorWhere written out column by column,
+// for databases without row value support, e.g. (a, b) > (1, 2) becomes a > 1 OR (a = 1 AND b > 2).
+// The values are repeated for every comparison so the returned args have to be used.
+func GetCursorWhereExpanded(comparisonSign ComparisonSign, columns []string, values []interface{}) (string, []interface{}) {
+	var ors []string
+	var args []interface{}
+	for i := range columns {
+		sign := comparisonSign
+		if i < len(columns)-1 {
+			sign = strictComparison(comparisonSign)
+		}
+
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, columns[j]+" = ?")
+			args = append(args, values[j])
+		}
+		ands = append(ands, columns[i]+" "+string(sign)+" ?")
+		args = append(args, values[i])
+		ors = append(ors, parenthese(strings.Join(ands, " AND ")))
+	}
+	return parenthese(strings.Join(ors, " OR ")), args
+}
+
+func strictComparison(comparisonSign ComparisonSign) ComparisonSign {
+	switch comparisonSign {
+	case ComparisonSignBiggerThanOrEqual:
+		return ComparisonSignBiggerThan
+	case ComparisonSignSmallerThanOrEqual:
+		return ComparisonSignSmallerThan
+	}
+	return comparisonSign
+}
+
 func EdgeLength(pagination ConnectionPagination, length int) int {
 	limit := GetLimit(pagination.Forward, pagination.Backward)
 	maxLength := limit - 1
@@ -299,3 +334,29 @@ func NewForwardPagination(first int, after *string) ConnectionPagination {
 		},
 	}
 }
+
+// defaultPageSize is the page size of a list query without pagination arguments
+const defaultPageSize = 100
+
+// NewPagination returns the pagination of a list query with the optional relay arguments, only one
+// direction can be used at once: first and after or last and before. Without arguments the first
+// page of defaultPageSize rows is returned.
+func NewPagination(first *int, after *string, last *int, before *string) (ConnectionPagination, error) {
+	forward := first != nil || after != nil
+	backward := last != nil || before != nil
+	switch {
+	case forward && backward:
+		return ConnectionPagination{}, errors.New("first and after can not be combined with last and before")
+	case backward:
+		if last == nil {
+			return ConnectionPagination{}, errors.New("before needs last to be set")
+		}
+		return NewBackwardPagination(*last, before), nil
+	case forward:
+		if first == nil {
+			return ConnectionPagination{}, errors.New("after needs first to be set")
+		}
+		return NewForwardPagination(*first, after), nil
+	}
+	return NewForwardPagination(defaultPageSize, nil), nil
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// AggregateColumn is a column of a model which can be aggregated or grouped by
+type AggregateColumn struct {
+	// Name is the graphql name of the column
+	Name        string
+	BoilerField *BoilerField
+	IsNumber    bool
+	IsTime      bool
+	IsGroup     bool
+}
+
+// GroupByValue is the value of the column in the {Model}GroupBy enum, like the sort values these are
+// mapped on the database columns so renamed columns keep the column name
+func (c *AggregateColumn) GroupByValue() string {
+	return strcase.ToScreamingSnake(ToGraphQLName(c.BoilerField.Name))
+}
+
+// AggregateColumns are the columns of a model used in the {model}Aggregate query, sum and avg are
+// over the numbers, min and max over the numbers and times and groupBy over the groups
+type AggregateColumns struct {
+	Numbers []*AggregateColumn
+	Times   []*AggregateColumn
+	Groups  []*AggregateColumn
+}
+
+// HasValues reports whether min and max can be computed
+func (a *AggregateColumns) HasValues() bool {
+	return len(a.Numbers) > 0 || len(a.Times) > 0
+}
+
+// GetAggregateColumns returns the aggregatable columns of the model, enums and foreign keys can be
+// grouped by, numeric columns are summed and averaged and numeric and time columns have a min and max.
+// Hidden columns and columns with an overridden type are left out.
+func GetAggregateColumns(cfg *Config, model *BoilerModel) *AggregateColumns {
+	a := &AggregateColumns{}
+	table := cfg.Tables.Get(model.TableName)
+	for _, field := range model.Fields {
+		column := table.Column(field.Name)
+		if column.Hidden || column.Type != "" || field.IsArray {
+			continue
+		}
+		// relations which are not a column of this table
+		if field.IsRelation && !field.IsForeignKey {
+			continue
+		}
+
+		c := &AggregateColumn{Name: ToGraphQLName(field.Name), BoilerField: field}
+		// relations keep the name of the model they point to
+		if column.Name != "" && !field.IsRelation {
+			c.Name = column.Name
+		}
+
+		lowerBoilerType := strings.ToLower(field.Type)
+		switch {
+		case field.IsEnum || (field.IsForeignKey && field.Relationship != nil):
+			c.IsGroup = true
+			a.Groups = append(a.Groups, c)
+		// ids are not summed, e.g. ID or ExternalID but not Paid
+		case strings.HasSuffix(field.Name, "ID"):
+			continue
+		case isNumberType(lowerBoilerType):
+			c.IsNumber = true
+			a.Numbers = append(a.Numbers, c)
+		case strings.Contains(lowerBoilerType, "time"):
+			c.IsTime = true
+			a.Times = append(a.Times, c)
+		}
+	}
+	return a
+}
+
+func isNumberType(lowerBoilerType string) bool {
+	if strings.HasPrefix(lowerBoilerType, "interface") {
+		return false
+	}
+	return strings.Contains(lowerBoilerType, "int") ||
+		strings.Contains(lowerBoilerType, "float") ||
+		strings.Contains(lowerBoilerType, "decimal")
+}
@@ -0,0 +1,323 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// CacheFile is where the fingerprints of the last generate run are kept, relative to the project root
+const CacheFile = ".sinatra/cache"
+
+// Cache keeps a fingerprint of the inputs every generated file was rendered from, together with a
+// hash of the file as it was written. A file whose inputs did not change and which was not touched
+// since does not have to be rendered, formatted and written again.
+// A nil cache is valid and never skips a file.
+type Cache struct {
+	filename string
+	mu       sync.Mutex
+	entries  map[string]CacheEntry
+	written  map[string]string
+}
+
+// CacheEntry is the cached state of a single generated file
+type CacheEntry struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+type cacheFile struct {
+	Version string                `json:"version"`
+	Files   map[string]CacheEntry `json:"files"`
+}
+
+// LoadCache reads the cache file, a missing, unreadable or outdated cache results in an empty cache
+func LoadCache(filename string) *Cache {
+	c := &Cache{
+		filename: filename,
+		entries:  map[string]CacheEntry{},
+		written:  map[string]string{},
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return c
+	}
+	var f cacheFile
+	// another version of sinatra renders different output from the same inputs
+	if err := json.Unmarshal(b, &f); err != nil || f.Version != Version {
+		return c
+	}
+	for name, e := range f.Files {
+		c.entries[name] = e
+	}
+	return c
+}
+
+// Fingerprint returns a hash over all given parts
+func Fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		// the length prefix keeps ("ab", "c") and ("a", "bc") apart
+		h.Write([]byte(strconv.Itoa(len(p)) + ":" + p)) //nolint:errcheck
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FingerprintJSON returns a hash over the json encoding of v, v is plain data like the config and must
+// not hold pointers which refer back
+func FingerprintJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// data which can't be encoded never matches, the file is generated every run
+		return Fingerprint(err.Error(), strconv.FormatInt(time.Now().UnixNano(), 10))
+	}
+	return Fingerprint(string(b))
+}
+
+// boilerModelInput is the metadata of a table without the related models, these refer back to it
+type boilerModelInput struct {
+	BoilerModel
+	Fields     []boilerFieldInput
+	UniqueKeys [][]string
+}
+
+type boilerFieldInput struct {
+	BoilerField
+	Relationship string
+}
+
+func newBoilerFieldInput(f *BoilerField) boilerFieldInput {
+	input := boilerFieldInput{BoilerField: *f}
+	if f.Relationship != nil {
+		input.Relationship = f.Relationship.Name
+	}
+	return input
+}
+
+// BoilerModelFingerprint returns a hash over the sqlboiler metadata of the table of the model, relations
+// are included by the name of the model they point to
+func BoilerModelFingerprint(m *BoilerModel) string {
+	input := boilerModelInput{BoilerModel: *m}
+	for _, f := range m.Fields {
+		input.Fields = append(input.Fields, newBoilerFieldInput(f))
+	}
+	for _, key := range m.UniqueKeys {
+		var names []string
+		for _, f := range key.Fields {
+			names = append(names, f.Name)
+		}
+		input.UniqueKeys = append(input.UniqueKeys, names)
+	}
+	return FingerprintJSON(input)
+}
+
+// BoilerFieldFingerprint returns a hash over the sqlboiler metadata of the field, a relation is included
+// by the name of the model it points to
+func BoilerFieldFingerprint(f *BoilerField) string {
+	if f == nil {
+		return ""
+	}
+	return FingerprintJSON(newBoilerFieldInput(f))
+}
+
+// RelatedModelNames returns the names of the models and of every model they have a relation with, in
+// both directions. What is generated for a relation depends on the models at both of its ends.
+func RelatedModelNames(models []*BoilerModel, all []*BoilerModel) map[string]bool {
+	names := map[string]bool{}
+	for _, m := range models {
+		names[m.Name] = true
+	}
+	related := map[string]bool{}
+	for _, m := range all {
+		for _, f := range m.Fields {
+			if f.Relationship == nil {
+				continue
+			}
+			if names[m.Name] {
+				related[f.Relationship.Name] = true
+			}
+			if names[f.Relationship.Name] {
+				related[m.Name] = true
+			}
+		}
+	}
+	for name := range related {
+		names[name] = true
+	}
+	return names
+}
+
+// BoilerModelsFingerprint returns a hash over the metadata of the models and the models related to them
+func BoilerModelsFingerprint(models []*BoilerModel, all []*BoilerModel) string {
+	names := RelatedModelNames(models, all)
+	var parts []string
+	for _, m := range all {
+		if names[m.Name] {
+			parts = append(parts, BoilerModelFingerprint(m))
+		}
+	}
+	return Fingerprint(parts...)
+}
+
+// DefinitionFingerprint returns a hash over a graphql type definition, a nil definition has an empty hash
+func DefinitionFingerprint(def *ast.Definition) string {
+	if def == nil {
+		return ""
+	}
+	parts := []string{string(def.Kind), def.Name, def.Description, strings.Join(def.Interfaces, ","), directivesText(def.Directives)}
+	for _, f := range def.Fields {
+		parts = append(parts, f.Name, f.Description, f.Type.String(), directivesText(f.Directives))
+		for _, a := range f.Arguments {
+			parts = append(parts, a.Name, a.Type.String(), valueText(a.DefaultValue), directivesText(a.Directives))
+		}
+	}
+	for _, v := range def.EnumValues {
+		parts = append(parts, v.Name, v.Description)
+	}
+	return Fingerprint(parts...)
+}
+
+// SchemaFingerprint returns a hash over every type of the graphql schema
+func SchemaFingerprint(schema *ast.Schema) string {
+	if schema == nil {
+		return ""
+	}
+	names := make([]string, 0, len(schema.Types))
+	for name := range schema.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = DefinitionFingerprint(schema.Types[name])
+	}
+	return Fingerprint(parts...)
+}
+
+func directivesText(directives ast.DirectiveList) string {
+	var b strings.Builder
+	for _, d := range directives {
+		b.WriteString("@" + d.Name + "(")
+		for _, a := range d.Arguments {
+			b.WriteString(a.Name + ":" + valueText(a.Value) + " ")
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+func valueText(v *ast.Value) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+// Unchanged reports whether filename was generated from the same input before and still has the content
+// it was generated with
+func (c *Cache) Unchanged(filename string, input string) bool {
+	if c == nil {
+		return false
+	}
+	key := cacheKey(filename)
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok || e.Input != input {
+		return false
+	}
+
+	output, err := hashFile(filename)
+	if err != nil || output != e.Output {
+		return false
+	}
+
+	c.mu.Lock()
+	c.written[key] = input
+	c.mu.Unlock()
+	return true
+}
+
+// Set records that filename was generated from the given input
+func (c *Cache) Set(filename string, input string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.written[cacheKey(filename)] = input
+	c.mu.Unlock()
+}
+
+// Save writes the files generated or kept in this run to the cache file, entries of files which were
+// not part of this run are kept. The hash of a file is taken now and not when the file is written, so
+// changes made by later steps of the pipeline are included.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f := cacheFile{
+		Version: Version,
+		Files:   c.entries,
+	}
+	names := make([]string, 0, len(c.written))
+	for name := range c.written {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		output, err := hashFile(name)
+		if err != nil {
+			continue
+		}
+		f.Files[name] = CacheEntry{Input: c.written[name], Output: output}
+	}
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "unable to encode cache")
+	}
+	if err := os.MkdirAll(filepath.Dir(c.filename), 0755); err != nil {
+		return errors.Wrap(err, "unable to create cache dir")
+	}
+	if err := ioutil.WriteFile(c.filename, b, 0644); err != nil {
+		return errors.Wrap(err, "unable to write cache")
+	}
+	return nil
+}
+
+// cacheKey makes filename relative to the working directory, so the cache stays valid when the
+// project is copied or moved
+func cacheKey(filename string) string {
+	if filepath.IsAbs(filename) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, filename); err == nil {
+				filename = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(filename))
+}
+
+func hashFile(filename string) (string, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return Fingerprint(string(b)), nil
+}
@@ -96,7 +96,7 @@ func GetFunctionNamesFromDir(dir string, ignore []string) ([]string, error) {
 
 	for _, pack := range packs {
 		for fileName, file := range pack.Files {
-			simpleName := strings.TrimPrefix(fileName, dir+"/")
+			simpleName := filepath.Base(fileName)
 			if !Contains(ignore, simpleName) {
 				a = append(a, GetFunctionNamesFromAstFile(file)...)
 			}
@@ -131,7 +131,7 @@ func GetResolverFunctionNamesFromDir(dir string, ignore []string) ([]string, err
 
 	for _, pack := range packs {
 		for fileName, file := range pack.Files {
-			simpleName := strings.TrimPrefix(fileName, dir+"/")
+			simpleName := filepath.Base(fileName)
 			if !Contains(ignore, simpleName) {
 				a = append(a, GetResolverFunctionNamesFromAstFile(file)...)
 			}
@@ -1,9 +1,13 @@
 package internal
 
 import (
+	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -55,6 +59,9 @@ type SchemaConfig struct {
 	Package         string   `yaml:"package,omitempty"`
 	Directives      []string `yaml:"directives,omitempty"`
 	SkipInputFields []string `yaml:"skipinputfields,omitempty"`
+	// PermanentDeleteDirectives guard the permanentlyDelete mutations of soft deleted tables, the mutations
+	// are only generated when a directive is set
+	PermanentDeleteDirectives []string `yaml:"permanentdeletedirectives,omitempty"`
 }
 
 type ModelConfig struct {
@@ -62,7 +69,15 @@ type ModelConfig struct {
 	Package string `yaml:"package,omitempty"`
 }
 
+// Supported database drivers
+const (
+	DriverPsql   = "psql"
+	DriverMysql  = "mysql"
+	DriverSqlite = "sqlite3"
+)
+
 type DatabaseConfig struct {
+	URL              string   `yaml:"url,omitempty"`
 	DBDriver         string   `yaml:"dbdriver"`
 	DBName           string   `yaml:"dbname"`
 	Schema           string   `yaml:"schema,omitempty"`
@@ -84,6 +99,105 @@ type DatabaseConfig struct {
 	Wipe             bool     `yaml:"wipe,omitempty"`
 	AddSoftDeletes   bool     `yaml:"addsoftdeletes,omitempty"`
 	StructTagCasing  string   `yaml:"structtagcasing,omitempty"`
+	TinyintAsInt     bool     `yaml:"tinyintasint,omitempty"`
+	DDL              string   `yaml:"ddl,omitempty"`
+}
+
+type TemplatesConfig struct {
+	Dirs      []string                 `yaml:"dirs,omitempty"`
+	Sqlboiler SqlboilerTemplatesConfig `yaml:"sqlboiler,omitempty"`
+}
+
+type SqlboilerTemplatesConfig struct {
+	Dirs         []string `yaml:"dirs,omitempty"`
+	Tags         []string `yaml:"tags,omitempty"`
+	Replacements []string `yaml:"replacements,omitempty"`
+}
+
+// Operations which can be skipped per table
+const (
+	OperationSingle          = "single"
+	OperationList            = "list"
+	OperationCreate          = "create"
+	OperationBatchCreate     = "batchCreate"
+	OperationUpdate          = "update"
+	OperationBatchUpdate     = "batchUpdate"
+	OperationBatchUpdateByID = "batchUpdateByID"
+	OperationDelete          = "delete"
+	OperationBatchDelete     = "batchDelete"
+	OperationAggregate       = "aggregate"
+	OperationUpsert          = "upsert"
+	OperationManyToMany      = "manyToMany"
+	OperationRestore         = "restore"
+	OperationPermanentDelete = "permanentlyDelete"
+)
+
+var Operations = []string{
+	OperationSingle,
+	OperationList,
+	OperationCreate,
+	OperationBatchCreate,
+	OperationUpdate,
+	OperationBatchUpdate,
+	OperationBatchUpdateByID,
+	OperationDelete,
+	OperationBatchDelete,
+	OperationAggregate,
+	OperationUpsert,
+	OperationManyToMany,
+	OperationRestore,
+	OperationPermanentDelete,
+}
+
+// TablesConfig are the overrides per table, keyed by table name
+type TablesConfig map[string]TableConfig
+
+type TableConfig struct {
+	// Name renames the graphql type of the table
+	Name    string                  `yaml:"name,omitempty"`
+	Skip    []string                `yaml:"skip,omitempty"`
+	Columns map[string]ColumnConfig `yaml:"columns,omitempty"`
+}
+
+type ColumnConfig struct {
+	// Name renames the graphql field of the column
+	Name string `yaml:"name,omitempty"`
+	// Type overrides the graphql type of the column
+	Type     string `yaml:"type,omitempty"`
+	ReadOnly bool   `yaml:"readonly,omitempty"`
+	Hidden   bool   `yaml:"hidden,omitempty"`
+}
+
+// Get returns the config of the table, the table name can be given as in the database or as in the
+// sqlboiler TableNames
+func (t TablesConfig) Get(tableName string) TableConfig {
+	for name, table := range t {
+		if sameDBName(name, tableName) {
+			return table
+		}
+	}
+	return TableConfig{}
+}
+
+// Column returns the config of the column, the column name can be given as in the database or as
+// the sqlboiler field name
+func (t TableConfig) Column(columnName string) ColumnConfig {
+	for name, column := range t.Columns {
+		if sameDBName(name, columnName) {
+			return column
+		}
+	}
+	return ColumnConfig{}
+}
+
+// Skips reports whether the operation should not be generated for the table
+func (t TableConfig) Skips(operation string) bool {
+	return SliceContains(t.Skip, operation)
+}
+
+// sameDBName compares a snake_case database name with its sqlboiler CamelCase counterpart
+func sameDBName(a, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, "_", ""), strings.ReplaceAll(b, "_", ""))
 }
 
 type Config struct {
@@ -94,6 +208,8 @@ type Config struct {
 	Resolver   ResolverConfig   `yaml:"resolver,omitempty"`
 	Federation FederationConfig `yaml:"federation,omitempty"`
 	Database   DatabaseConfig   `yaml:"database,omitempty"`
+	Templates  TemplatesConfig  `yaml:"templates,omitempty"`
+	Tables     TablesConfig     `yaml:"tables,omitempty"`
 }
 
 var path2regex = strings.NewReplacer(
@@ -110,12 +226,16 @@ func DefaultConfig() *Config {
 		Helper:   BaseConfig{DirName: "helpers", Package: "helpers"},
 		Graph:    BaseConfig{DirName: "graph", Package: "graph"},
 		Schema:   SchemaConfig{DirName: "schema", Package: "schema"},
-		Database: DatabaseConfig{DBDriver: "psql", Debug: false, AddGlobal: true, AddPanic: false, NoContext: false, NoTests: false, NoHooks: false, NoRowsAffected: false, NoAutoTimestamps: false, AddSoftDeletes: true, Wipe: true, StructTagCasing: "camel"},
+		Database: DatabaseConfig{DBDriver: DriverPsql, Debug: false, AddGlobal: true, AddPanic: false, NoContext: false, NoTests: false, NoHooks: false, NoRowsAffected: false, NoAutoTimestamps: false, AddSoftDeletes: true, Wipe: true, StructTagCasing: "camel"},
 	}
 }
 
-// LoadConfig reads the sinatra.yml config file
-func LoadConfig(filename string) (*Config, error) {
+// profilesKey is the config section holding the named profiles
+const profilesKey = "profiles"
+
+// LoadConfig reads the sinatra.yml config file. When a profile is given its section below
+// profiles is layered over the base config. Placeholders in strings are interpolated afterwards.
+func LoadConfig(filename string, profile string) (*Config, error) {
 	config := DefaultConfig()
 
 	b, err := ioutil.ReadFile(filename)
@@ -123,13 +243,132 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, errors.Wrap(err, "unable to read config")
 	}
 
+	b, err = applyProfile(b, profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load profile %s", profile)
+	}
+
 	if err := yaml.UnmarshalStrict(b, config); err != nil {
 		return nil, errors.Wrap(err, "unable to parse config")
 	}
 
+	if err := interpolateStrings(reflect.ValueOf(config)); err != nil {
+		return nil, errors.Wrap(err, "unable to interpolate config")
+	}
+
+	if err := config.Database.applyURL(); err != nil {
+		return nil, errors.Wrap(err, "invalid database url")
+	}
+
 	return config, nil
 }
 
+// applyProfile merges the profile into the base config and removes the profiles section
+func applyProfile(b []byte, profile string) ([]byte, error) {
+	var raw map[interface{}]interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		raw = map[interface{}]interface{}{}
+	}
+
+	profiles, _ := raw[profilesKey].(map[interface{}]interface{})
+	delete(raw, profilesKey)
+
+	if profile != "" {
+		overlay, ok := profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %s is not defined", profile)
+		}
+		if overlay, ok := overlay.(map[interface{}]interface{}); ok {
+			mergeConfigMaps(raw, overlay)
+		}
+	}
+
+	return yaml.Marshal(raw)
+}
+
+// mergeConfigMaps merges the overlay into the base, nested sections are merged and every other
+// value, lists included, is replaced
+func mergeConfigMaps(base, overlay map[interface{}]interface{}) {
+	for key, value := range overlay {
+		baseSection, baseIsMap := base[key].(map[interface{}]interface{})
+		section, isMap := value.(map[interface{}]interface{})
+		if baseIsMap && isMap {
+			mergeConfigMaps(baseSection, section)
+			continue
+		}
+		base[key] = value
+	}
+}
+
+// urlDrivers maps the schemes of a database url to the drivers
+var urlDrivers = map[string]string{
+	"postgres":   DriverPsql,
+	"postgresql": DriverPsql,
+	"psql":       DriverPsql,
+	"mysql":      DriverMysql,
+	"sqlite":     DriverSqlite,
+	"sqlite3":    DriverSqlite,
+	"file":       DriverSqlite,
+}
+
+// applyURL sets the driver and connection settings from the url, they take precedence over the
+// separate settings
+func (c *DatabaseConfig) applyURL() error {
+	if c.URL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return err
+	}
+	driver, ok := urlDrivers[u.Scheme]
+	if !ok {
+		return fmt.Errorf("unsupported scheme %s", u.Scheme)
+	}
+	c.DBDriver = driver
+
+	// sqlite only knows about the database file, e.g. sqlite3://./dev.db or sqlite3:///abs/dev.db
+	if driver == DriverSqlite {
+		c.DBName = u.Host + u.Path
+		if u.Opaque != "" {
+			c.DBName = u.Opaque
+		}
+		return nil
+	}
+
+	if host := u.Hostname(); host != "" {
+		c.Host = host
+	}
+	if port := u.Port(); port != "" {
+		c.Port = port
+	}
+	if u.User != nil {
+		c.User = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			c.Password = password
+		}
+	}
+	if name := strings.TrimPrefix(u.Path, "/"); name != "" {
+		c.DBName = name
+	}
+
+	query := u.Query()
+	if sslmode := query.Get("sslmode"); sslmode != "" {
+		c.SSLMode = sslmode
+	}
+	if tls := query.Get("tls"); tls != "" && driver == DriverMysql {
+		c.SSLMode = tls
+	}
+	if schema := query.Get("search_path"); schema != "" {
+		c.Schema = schema
+	}
+	return nil
+}
+
 // LoadDefaultConfig loads the default config so that it is ready to be used
 func LoadDefaultConfig() (*Config, error) {
 	config := DefaultConfig()
@@ -138,7 +377,7 @@ func LoadDefaultConfig() (*Config, error) {
 
 // LoadConfigFromDefaultLocations looks for a config file in the current directory, and all parent directories
 // walking up the tree. The closest config file will be returned.
-func LoadConfigFromDefaultLocations() (*Config, error) {
+func LoadConfigFromDefaultLocations(profile string) (*Config, error) {
 	cfgFile, err := findCfg()
 	if err != nil {
 		return nil, err
@@ -148,7 +387,7 @@ func LoadConfigFromDefaultLocations() (*Config, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to enter config dir")
 	}
-	return LoadConfig(cfgFile)
+	return LoadConfig(cfgFile, profile)
 }
 
 // GenerateGqlgenConfig
@@ -161,12 +400,12 @@ func LoadGqlgenConfig(cfg *Config) (*gqlcon.Config, error) {
 		"deprecated": {SkipRuntime: true},
 	}
 
-	config.SchemaFilename = gqlcon.StringList{cfg.Schema.DirName + "/*.graphql"}
-	config.Exec.Filename = cfg.Graph.DirName + "/exec.go"
+	config.SchemaFilename = gqlcon.StringList{filepath.Join(cfg.Schema.DirName, "*.graphql")}
+	config.Exec.Filename = filepath.Join(cfg.Graph.DirName, "exec.go")
 	config.Exec.Package = cfg.Graph.Package
-	config.Model.Filename = cfg.Graph.DirName + "/models.go"
+	config.Model.Filename = filepath.Join(cfg.Graph.DirName, "models.go")
 	config.Model.Package = cfg.Graph.Package
-	config.Resolver.Filename = cfg.Resolver.DirName + "/resolver.go"
+	config.Resolver.Filename = filepath.Join(cfg.Resolver.DirName, "resolver.go")
 	config.Models = gqlcon.TypeMap{
 		"ConnectionBackwardPagination": gqlcon.TypeMapEntry{
 			Model: gqlcon.StringList{"github.com/frankie-seb/sinatra/helpers.ConnectionBackwardPagination"},
@@ -183,8 +422,8 @@ func LoadGqlgenConfig(cfg *Config) (*gqlcon.Config, error) {
 	}
 
 	if cfg.Federation.Activate {
-		config.AutoBind = gqlcon.StringList{cfg.Graph.DirName}
-		config.Federation.Filename = cfg.Graph.DirName + "/federation.go"
+		config.AutoBind = gqlcon.StringList{path.Join(GetRootImportPath(), cfg.Graph.DirName)}
+		config.Federation.Filename = filepath.Join(cfg.Graph.DirName, "federation.go")
 		config.Federation.Package = cfg.Graph.Package
 	}
 
@@ -250,9 +489,61 @@ func LoadGqlgenConfig(cfg *Config) (*gqlcon.Config, error) {
 		config.Sources = append(config.Sources, &ast.Source{Name: filename, Input: string(schemaRaw)})
 	}
 
+	for typeName, fields := range tablesFieldRenames(cfg) {
+		entry := config.Models[typeName]
+		if entry.Fields == nil {
+			entry.Fields = map[string]gqlcon.TypeMapField{}
+		}
+		for name, field := range fields {
+			entry.Fields[name] = field
+		}
+		config.Models[typeName] = entry
+	}
+
 	return config, nil
 }
 
+// tablesFieldRenames binds the renamed fields of the tables config to the go name of their column, so
+// the generated structs still match the sqlboiler models
+func tablesFieldRenames(cfg *Config) map[string]map[string]gqlcon.TypeMapField {
+	renames := map[string]map[string]gqlcon.TypeMapField{}
+	if len(cfg.Tables) == 0 {
+		return renames
+	}
+
+	boilerModels, _ := LoadBoilerModels(cfg)
+	for _, model := range boilerModels {
+		table := cfg.Tables.Get(model.TableName)
+		for _, field := range model.Fields {
+			column := table.Column(field.Name)
+			if column.Name == "" || field.IsRelation {
+				continue
+			}
+			var typeNames []string
+			for _, suffix := range []string{"", "Where", "CreateInput", "UpdateInput"} {
+				typeNames = append(typeNames, model.GraphName+suffix)
+			}
+			for _, foreignKey := range model.Fields {
+				if foreignKey.IsForeignKey && foreignKey.Relationship != nil {
+					typeNames = append(typeNames, NestedCreateInputName(model, foreignKey))
+				}
+			}
+			for _, typeName := range typeNames {
+				if renames[typeName] == nil {
+					renames[typeName] = map[string]gqlcon.TypeMapField{}
+				}
+				renames[typeName][column.Name] = gqlcon.TypeMapField{FieldName: ToGraphQLName(field.Name)}
+			}
+		}
+	}
+	return renames
+}
+
+// FindConfigFile looks for a config file in the current directory and all parent directories
+func FindConfigFile() (string, error) {
+	return findCfg()
+}
+
 // findCfg searches for the config file in this directory and all parents up the tree
 // looking for the closest match
 func findCfg() (string, error) {
@@ -0,0 +1,436 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// configSchemaID is where the schema of sinatra.yml is published
+const configSchemaID = "https://raw.githubusercontent.com/frankie-seb/sinatra/main/sinatra.schema.json"
+
+// configEnums are the allowed values of config keys, by their path
+var configEnums = map[string][]string{
+	"database.dbdriver":        {DriverPsql, DriverMysql, DriverSqlite},
+	"database.structtagcasing": {"camel", "snake"},
+	"tables.*.skip[]":          Operations,
+}
+
+// ConfigJSONSchema generates the JSON Schema of sinatra.yml from the Config structs
+func ConfigJSONSchema() map[string]interface{} {
+	schema := jsonSchemaForType(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = configSchemaID
+	schema["title"] = "sinatra.yml"
+
+	// profiles hold partial configs which are layered over the base config
+	properties := schema["properties"].(map[string]interface{})
+	properties[profilesKey] = map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"$ref": "#"},
+	}
+	return schema
+}
+
+func jsonSchemaForType(t reflect.Type, path string) map[string]interface{} {
+	if values, ok := configEnums[path]; ok {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return jsonSchemaForType(t.Elem(), path)
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			key := yamlKey(t.Field(i))
+			if key == "" {
+				continue
+			}
+			properties[key] = jsonSchemaForType(t.Field(i).Type, joinConfigPath(path, key))
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": jsonSchemaForType(t.Elem(), joinConfigPath(path, "*")),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": jsonSchemaForType(t.Elem(), path+"[]"),
+		}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// yaml reads unquoted numbers like ports and passwords into strings as well
+		return map[string]interface{}{"type": []string{"string", "number"}}
+	}
+}
+
+func yamlKey(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	key := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if key == "-" {
+		return ""
+	}
+	if key == "" {
+		return strings.ToLower(f.Name)
+	}
+	return key
+}
+
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// ConfigProblem is an issue found while validating the config
+type ConfigProblem struct {
+	Path    string
+	Message string
+}
+
+func (p ConfigProblem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// ValidateConfigFile checks the config file against the schema and, when it is valid, the loaded
+// config of the profile for contradictions. Only failing to read the file is returned as error.
+func ValidateConfigFile(filename string, profile string) ([]ConfigProblem, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read config")
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return []ConfigProblem{{Message: "invalid yaml: " + strings.TrimPrefix(err.Error(), "yaml: ")}}, nil
+	}
+	if doc == nil {
+		doc = map[interface{}]interface{}{}
+	}
+
+	schema := ConfigJSONSchema()
+	problems := validateJSONSchema(schema, schema, doc, "")
+	if len(problems) > 0 {
+		return problems, nil
+	}
+
+	cfg, err := LoadConfig(filename, profile)
+	if err != nil {
+		return []ConfigProblem{{Message: errors.Cause(err).Error()}}, nil
+	}
+	return ValidateConfig(cfg, filepath.Dir(filename)), nil
+}
+
+// validateJSONSchema validates the yaml document against the subset of JSON Schema which
+// ConfigJSONSchema generates
+func validateJSONSchema(root, schema map[string]interface{}, v interface{}, path string) []ConfigProblem {
+	if ref, ok := schema["$ref"].(string); ok && ref == "#" {
+		schema = root
+	}
+	// an empty key is read as the zero value
+	if v == nil {
+		return nil
+	}
+
+	if !matchesJSONType(schema["type"], v) {
+		return []ConfigProblem{{Path: path, Message: fmt.Sprintf("expected %s but got %s", describeJSONType(schema["type"]), describeYAMLValue(v))}}
+	}
+
+	// placeholders are interpolated when the config is loaded, ValidateConfig checks the values
+	if values, ok := schema["enum"].([]string); ok {
+		if s, _ := v.(string); !SliceContains(values, s) && !placeholderRegex.MatchString(s) {
+			return []ConfigProblem{{Path: path, Message: fmt.Sprintf("%v is not one of %s", v, strings.Join(values, ", "))}}
+		}
+	}
+
+	var problems []ConfigProblem
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, fmt.Sprint(k))
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			keyPath := joinConfigPath(path, key)
+			if property, ok := properties[key].(map[string]interface{}); ok {
+				problems = append(problems, validateJSONSchema(root, property, value[key], keyPath)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				problems = append(problems, validateJSONSchema(root, additional, value[key], keyPath)...)
+			case bool:
+				if !additional {
+					problems = append(problems, ConfigProblem{Path: keyPath, Message: unknownKeyMessage(key, properties)})
+				}
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				problems = append(problems, validateJSONSchema(root, items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return problems
+}
+
+func matchesJSONType(schemaType interface{}, v interface{}) bool {
+	switch t := schemaType.(type) {
+	case []string:
+		for _, single := range t {
+			if matchesJSONType(single, v) {
+				return true
+			}
+		}
+		return false
+	case string:
+		switch t {
+		case "object":
+			_, ok := v.(map[interface{}]interface{})
+			return ok
+		case "array":
+			_, ok := v.([]interface{})
+			return ok
+		case "boolean":
+			_, ok := v.(bool)
+			return ok
+		case "integer":
+			switch v.(type) {
+			case int, int64, uint64:
+				return true
+			}
+			return false
+		case "number":
+			switch v.(type) {
+			case int, int64, uint64, float64:
+				return true
+			}
+			return false
+		case "string":
+			_, ok := v.(string)
+			return ok
+		}
+	}
+	return true
+}
+
+func describeJSONType(schemaType interface{}) string {
+	if t, ok := schemaType.([]string); ok {
+		// every string field accepts numbers as well, no need to confuse with that
+		return t[0]
+	}
+	return fmt.Sprint(schemaType)
+}
+
+func describeYAMLValue(v interface{}) string {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		return "a section"
+	case []interface{}:
+		return "a list"
+	case bool:
+		return fmt.Sprintf("boolean %v", value)
+	case string:
+		return fmt.Sprintf("string %q", value)
+	default:
+		return fmt.Sprintf("number %v", value)
+	}
+}
+
+func unknownKeyMessage(key string, properties map[string]interface{}) string {
+	known := make([]string, 0, len(properties))
+	for k := range properties {
+		known = append(known, k)
+	}
+	sort.Strings(known)
+
+	message := "unknown key " + key
+	best, bestDistance := "", 3
+	for _, k := range known {
+		if d := levenshtein(strings.ToLower(key), k); d < bestDistance {
+			best, bestDistance = k, d
+		}
+	}
+	if best != "" {
+		return message + ", did you mean " + best + "?"
+	}
+	return message + ", expected one of " + strings.Join(known, ", ")
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// ValidateConfig checks a loaded config for missing settings, missing files and directories and settings
+// which contradict each other. The paths in the config are relative to configDir, the directory of the
+// config file.
+func ValidateConfig(cfg *Config, configDir string) []ConfigProblem {
+	var problems []ConfigProblem
+	add := func(path, format string, args ...interface{}) {
+		problems = append(problems, ConfigProblem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	resolve := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(configDir, path)
+	}
+
+	for path, dir := range map[string]string{
+		"model.dirname":    cfg.Model.DirName,
+		"helper.dirname":   cfg.Helper.DirName,
+		"graph.dirname":    cfg.Graph.DirName,
+		"schema.dirname":   cfg.Schema.DirName,
+		"resolver.dirname": cfg.Resolver.DirName,
+	} {
+		if dir == "" {
+			add(path, "is required")
+			continue
+		}
+		// missing directories are created while generating, a file in their place is not
+		if info, err := os.Stat(resolve(dir)); err == nil && !info.IsDir() {
+			add(path, "%s is not a directory", dir)
+		}
+	}
+
+	db := cfg.Database
+	// the values of the config file are checked against the schema before they are interpolated
+	for path, value := range map[string]string{
+		"database.dbdriver":        db.DBDriver,
+		"database.structtagcasing": db.StructTagCasing,
+	} {
+		if values := configEnums[path]; !SliceContains(values, value) {
+			add(path, "%s is not one of %s", value, strings.Join(values, ", "))
+		}
+	}
+	for name, table := range cfg.Tables {
+		for i, operation := range table.Skip {
+			if !SliceContains(Operations, operation) {
+				add(fmt.Sprintf("tables.%s.skip[%d]", name, i), "%s is not one of %s", operation, strings.Join(Operations, ", "))
+			}
+		}
+	}
+	if db.DBName == "" && db.DDL == "" {
+		add("database.dbname", "is required, it's the database name or the database file for sqlite3")
+	}
+	if cfg.Federation.Activate && db.Schema == "" && db.DBDriver == DriverPsql {
+		add("federation.activate", "federation needs database.schema to be set")
+	}
+	if db.DBDriver == DriverMysql && db.Schema != "" && db.Schema != db.DBName {
+		add("database.schema", "mysql has no schemas besides the database, remove it or set it to the dbname")
+	}
+	if db.DBDriver == DriverSqlite && db.Schema != "" {
+		add("database.schema", "sqlite3 has no schemas, remove it")
+	}
+	if db.TinyintAsInt && db.DBDriver != DriverMysql {
+		add("database.tinyintasint", "is only supported by mysql")
+	}
+	if db.DDL != "" {
+		if db.DBDriver != DriverPsql {
+			add("database.ddl", "generating from sql files is only supported for psql")
+		}
+		if _, err := os.Stat(resolve(db.DDL)); err != nil {
+			add("database.ddl", "%s does not exist", db.DDL)
+		}
+	}
+	if !cfg.Federation.Activate && (cfg.Federation.ForeignIDs != nil || cfg.Federation.JoinRelationships != nil) {
+		add("federation", "foreignids and joinrelationships are only used when federation is activated")
+	}
+	if cfg.Federation.ForeignIDs != nil {
+		for i, f := range *cfg.Federation.ForeignIDs {
+			if f.Table == "" || f.Column == "" {
+				add(fmt.Sprintf("federation.foreignids[%d]", i), "table and column are required")
+			}
+		}
+	}
+	if cfg.Federation.JoinRelationships != nil {
+		for i, r := range *cfg.Federation.JoinRelationships {
+			if r.From == "" || r.To == "" || r.Via == "" {
+				add(fmt.Sprintf("federation.joinrelationships[%d]", i), "from, to and via are required")
+			}
+		}
+	}
+
+	for i, dir := range cfg.Templates.Dirs {
+		if info, err := os.Stat(resolve(dir)); err != nil || !info.IsDir() {
+			add(fmt.Sprintf("templates.dirs[%d]", i), "directory %s does not exist", dir)
+		}
+	}
+	for i, dir := range cfg.Templates.Sqlboiler.Dirs {
+		if info, err := os.Stat(resolve(dir)); err != nil || !info.IsDir() {
+			add(fmt.Sprintf("templates.sqlboiler.dirs[%d]", i), "directory %s does not exist", dir)
+		}
+	}
+	for i, replacement := range cfg.Templates.Sqlboiler.Replacements {
+		path := fmt.Sprintf("templates.sqlboiler.replacements[%d]", i)
+		parts := strings.Split(replacement, ";")
+		if len(parts) != 2 {
+			add(path, "expected original;replacement but got %s", replacement)
+			continue
+		}
+		if _, err := os.Stat(resolve(parts[1])); err != nil {
+			add(path, "replacement %s does not exist", parts[1])
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Path < problems[j].Path
+	})
+	return problems
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// HelperFiles are the files the helper plugin renders into the helper directory
+var HelperFiles = []string{
+	"base.go",
+	"lib.go",
+	"common_filter.go",
+	"preload.go",
+}
+
+// GeneratedFilePatterns returns the glob patterns, relative to the project root, of every
+// file which is (re)written by a generate run
+func GeneratedFilePatterns(cfg *Config) []string {
+	patterns := []string{
+		filepath.Join(cfg.Model.DirName, "*.go"),
+		filepath.Join(cfg.Schema.DirName, "*_gen.graphql"),
+		filepath.Join(cfg.Resolver.DirName, "*_gen.go"),
+		filepath.Join(cfg.Resolver.DirName, "resolver.go"),
+		filepath.Join(cfg.Graph.DirName, "exec.go"),
+		filepath.Join(cfg.Graph.DirName, "models.go"),
+		filepath.Join(cfg.Graph.DirName, "federation.go"),
+	}
+	for _, f := range HelperFiles {
+		patterns = append(patterns, filepath.Join(cfg.Helper.DirName, f))
+	}
+	return patterns
+}
+
+// GeneratedFiles returns the generated files which currently exist below root, relative to root
+func GeneratedFiles(root string, cfg *Config) ([]string, error) {
+	var files []string
+	for _, pattern := range GeneratedFilePatterns(cfg) {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to glob generated files %s", pattern)
+		}
+		for _, m := range matches {
+			rel, err := filepath.Rel(root, m)
+			if err != nil {
+				return nil, err
+			}
+			files = AppendIfMissing(files, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// IsGeneratedFile reports whether the file, relative to the project root, is written by a generate run
+func IsGeneratedFile(cfg *Config, name string) bool {
+	name = filepath.Clean(name)
+	for _, pattern := range GeneratedFilePatterns(cfg) {
+		if ok, _ := filepath.Match(pattern, name); ok { //nolint:errcheck
+			return true
+		}
+	}
+	return false
+}
+
+// FileDiff is the difference of a single generated file between two trees
+type FileDiff struct {
+	Name string
+	Diff string
+}
+
+// DiffGeneratedFiles compares the generated files of the current tree with the ones in the
+// generated tree and returns a unified diff per changed, added or removed file
+func DiffGeneratedFiles(currentRoot, generatedRoot string, cfg *Config) ([]FileDiff, error) {
+	current, err := GeneratedFiles(currentRoot, cfg)
+	if err != nil {
+		return nil, err
+	}
+	generated, err := GeneratedFiles(generatedRoot, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	names := current
+	for _, name := range generated {
+		names = AppendIfMissing(names, name)
+	}
+	sort.Strings(names)
+
+	var diffs []FileDiff
+	for _, name := range names {
+		a, err := readOptionalFile(filepath.Join(currentRoot, name))
+		if err != nil {
+			return nil, err
+		}
+		b, err := readOptionalFile(filepath.Join(generatedRoot, name))
+		if err != nil {
+			return nil, err
+		}
+		if a == b {
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(a),
+			B:        difflib.SplitLines(b),
+			FromFile: "a/" + name,
+			ToFile:   "b/" + name,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not diff %s", name)
+		}
+		diffs = append(diffs, FileDiff{Name: name, Diff: diff})
+	}
+	return diffs, nil
+}
+
+// CopyProject copies the project in src to dst so generation can run without touching src.
+// Version control and node modules are skipped.
+func CopyProject(src, dst string) error {
+	skipDirs := []string{".git", "node_modules"}
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			if rel != "." && SliceContains(skipDirs, info.Name()) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "unable to copy %s", rel)
+		}
+		return ioutil.WriteFile(target, content, info.Mode().Perm())
+	})
+}
+
+func readOptionalFile(filename string) (string, error) {
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read generated file")
+	}
+	return strings.ReplaceAll(string(b), "\r\n", "\n"), nil
+}
@@ -31,7 +31,7 @@ func getModelsFromSchema(schema *ast.Schema, boilerModels []*BoilerModel) (model
 				}
 
 				// We will try to find a corresponding boiler struct
-				boilerModel := FindBoilerModel(boilerModels, getBaseModelFromName(modelName))
+				boilerModel := FindBoilerModelByGraphName(boilerModels, getBaseModelFromName(modelName))
 
 				isInput := doesEndWith(modelName, "Input")
 				isCreateInput := doesEndWith(modelName, "CreateInput")
@@ -100,6 +100,9 @@ func getModelsFromSchema(schema *ast.Schema, boilerModels []*BoilerModel) (model
 }
 
 func getBaseModelFromName(v string) string {
+	if m := nestedCreateInputRe.FindStringSubmatch(v); m != nil {
+		return m[1]
+	}
 	v = safeTrim(v, "CreateInput")
 	v = safeTrim(v, "UpdateInput")
 	v = safeTrim(v, "Input")
@@ -127,7 +130,6 @@ func doesEndWith(s string, suffix string) bool {
 func getGraphqlFieldName(cfg *config.Config, modelName string, field *ast.FieldDefinition) string {
 	name := field.Name
 	if nameOveride := cfg.Models[modelName].Fields[field.Name].FieldName; nameOveride != "" {
-		// TODO: map overrides to sqlboiler the other way around?
 		name = nameOveride
 	}
 	return name
@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// placeholderRegex matches ${ENV_VAR}, ${ENV_VAR:-default} and ${file:/path/to/secret},
+// a placeholder is escaped with a second dollar sign: $${NOT_REPLACED}
+var placeholderRegex = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+const filePlaceholderPrefix = "file:"
+
+// interpolate replaces the placeholders in v with environment variables and file contents
+func interpolate(v string) (string, error) {
+	var firstErr error
+	result := placeholderRegex.ReplaceAllStringFunc(v, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		value, err := resolvePlaceholder(match[2 : len(match)-1])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return value
+	})
+	return result, firstErr
+}
+
+func resolvePlaceholder(name string) (string, error) {
+	if strings.HasPrefix(name, filePlaceholderPrefix) {
+		filename := strings.TrimPrefix(name, filePlaceholderPrefix)
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", fmt.Errorf("unable to read secret file %s: %v", filename, err)
+		}
+		// secret files usually end with a newline which is not part of the secret
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+
+	def := ""
+	hasDefault := false
+	if i := strings.Index(name, ":-"); i >= 0 {
+		name, def, hasDefault = name[:i], name[i+2:], true
+	}
+	if value, ok := os.LookupEnv(name); ok {
+		return value, nil
+	}
+	if hasDefault {
+		return def, nil
+	}
+	return "", fmt.Errorf("environment variable %s is not set", name)
+}
+
+// interpolateStrings replaces the placeholders in all strings reachable from v, which has to be a pointer
+func interpolateStrings(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return interpolateStrings(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if err := interpolateStrings(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := interpolateStrings(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// map values are not addressable, interpolate a copy and put it back
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			if err := interpolateStrings(value); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, value)
+		}
+	case reflect.String:
+		s, err := interpolate(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	}
+	return nil
+}
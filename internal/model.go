@@ -90,6 +90,10 @@ type Field struct {
 	// For unmapped schema columns
 	IsID      bool
 	IsIDTable string
+
+	// NestedCreateInput is the input of the to-many relations of create inputs, these are created
+	// together with the model
+	NestedCreateInput *Model
 }
 
 type FieldConfig struct {
@@ -165,8 +169,9 @@ func enhanceModelsWithFields(enums []*Enum, schema *ast.Schema, cfg *config.Conf
 			if err != nil {
 				log.Err(err).Msg("could not get field type from graphql schema")
 			}
-			jsonName := getGraphqlFieldName(cfg, m.Name, field)
-			name := gqlgenTemplates.ToGo(jsonName)
+			// renamed fields keep the go name of their column, see tablesFieldRenames
+			jsonName := field.Name
+			name := gqlgenTemplates.ToGo(getGraphqlFieldName(cfg, m.Name, field))
 
 			// just some (old) Relay clutter which is not needed anymore + we won't do anything with it
 			// in our database converts.
@@ -286,12 +291,28 @@ func enhanceModelsWithFields(enums []*Enum, schema *ast.Schema, cfg *config.Conf
 	for _, m := range models {
 		for _, f := range m.Fields {
 			if f.BoilerField.Relationship != nil {
-				f.Relationship = findModel(models, f.BoilerField.Relationship.Name)
+				f.Relationship = findModel(models, f.BoilerField.Relationship.GraphName)
+			}
+			if m.IsInput && f.BoilerField.IsArray {
+				if foreignKey := HasManyForeignKey(m.BoilerModel, &f.BoilerField); foreignKey != nil {
+					f.NestedCreateInput = findModel(models, NestedCreateInputName(f.BoilerField.Relationship, foreignKey))
+				}
 			}
 		}
 	}
 }
 
+// NestedCreateFields are the to-many relations of the input which are created together with the model
+func (m *Model) NestedCreateFields() []*Field {
+	var a []*Field
+	for _, f := range m.Fields {
+		if f.NestedCreateInput != nil {
+			a = append(a, f)
+		}
+	}
+	return a
+}
+
 func enhanceModelsWithPreloadArray(modelPackage string, models []*Model) {
 	// first adding basic first level relations
 	for _, model := range models {
@@ -326,7 +347,7 @@ func getPreloadMapForModel(modelPackage string, model *Model) map[string]ColumnS
 			continue
 		}
 		key := field.JSONName
-		name := fmt.Sprintf("%v.%vRels.%v", modelPackage, model.Name, foreignKeyToRel(field.BoilerField.Name))
+		name := fmt.Sprintf("%v.%vRels.%v", modelPackage, model.BoilerModel.Name, foreignKeyToRel(field.BoilerField.Name))
 		setting := ColumnSetting{
 			Name:                  name,
 			IDAvailable:           !field.IsPlural,
@@ -454,7 +475,7 @@ func getConvertConfig(enums []*Enum, model *Model, field *Field) (fc FieldConfig
 			if field.IsPrimaryID {
 				fc.ToGraphQL = model.Name + "IDToGraphQL(" + fc.ToGraphQL + ")"
 			} else if field.IsNumberID && field.BoilerField.IsRelation {
-				fc.ToGraphQL = field.BoilerField.Relationship.Name + "IDToGraphQL(" + fc.ToGraphQL + ")"
+				fc.ToGraphQL = field.BoilerField.Relationship.GraphName + "IDToGraphQL(" + fc.ToGraphQL + ")"
 			} else if field.IsNumberID && field.IsID && !strings.HasPrefix(graphType, "*") {
 				fc.ToGraphQL = "base_helpers.IDToGraphQL(" + fc.ToGraphQL + ",\"" + field.IsIDTable + "\")"
 			} else if field.IsNumberID && field.IsID && strings.HasPrefix(graphType, "*") {
@@ -464,7 +485,9 @@ func getConvertConfig(enums []*Enum, model *Model, field *Field) (fc FieldConfig
 			isInt := strings.HasPrefix(strings.ToLower(boilType), "int") && !strings.HasPrefix(strings.ToLower(boilType), "uint")
 			isTime := strings.HasPrefix(strings.ToLower(boilType), "time")
 
-			if strings.HasPrefix(boilType, "null") {
+			if boilType == "null.Int64" {
+				fc.ToBoiler = fmt.Sprintf("base_helpers.IDToBoilerNullInt64(m.%v)", field.BoilerField.Name)
+			} else if strings.HasPrefix(boilType, "null") {
 				fc.ToBoiler = fmt.Sprintf("base_helpers.IDToBoilerNullInt(m.%v)", field.BoilerField.Name)
 				if isInt {
 					fc.ToBoiler = fmt.Sprintf("base_helpers.NullUintToNullInt(%v)", fc.ToBoiler)
@@ -475,7 +498,7 @@ func getConvertConfig(enums []*Enum, model *Model, field *Field) (fc FieldConfig
 			} else {
 				fc.ToBoiler = fmt.Sprintf("base_helpers.IDToBoiler(%v)", fc.ToBoiler)
 				if isInt {
-					fc.ToBoiler = fmt.Sprintf("int(%v)", fc.ToBoiler)
+					fc.ToBoiler = fmt.Sprintf("%v(%v)", boilType, fc.ToBoiler)
 				}
 			}
 
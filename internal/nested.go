@@ -0,0 +1,41 @@
+package internal
+
+import "regexp"
+
+// e.g. PostCreateWithoutUserInput
+var nestedCreateInputRe = regexp.MustCompile(`^(\w+?)CreateWithout\w+Input$`)
+
+// HasManyForeignKey returns the foreign key of the related models of the to-many relation which points
+// back to the model, e.g. posts.user_id for the posts of a user. Relations through a join table have no
+// foreign key in the related model and nil is returned.
+func HasManyForeignKey(model *BoilerModel, relation *BoilerField) *BoilerField {
+	if !relation.IsRelation || !relation.IsArray || relation.Relationship == nil {
+		return nil
+	}
+	related := relation.Relationship
+
+	var keys []*BoilerField
+	for _, field := range related.Fields {
+		if field.IsForeignKey && field.Relationship != nil && field.Relationship.Name == model.Name {
+			keys = append(keys, field)
+		}
+	}
+	if len(keys) == 1 {
+		return keys[0]
+	}
+	// sqlboiler prefixes the relation with the foreign key when it is not named after the model
+	// e.g. the AuthorPosts of a user for posts.author_id
+	for _, key := range keys {
+		if relation.Name == key.RelationshipName+related.PluralName ||
+			(relation.Name == related.PluralName && key.RelationshipName == model.Name) {
+			return key
+		}
+	}
+	return nil
+}
+
+// NestedCreateInputName is the input of the related models of a to-many relation in the create input,
+// it is their create input without the foreign key which is set from the created model
+func NestedCreateInputName(related *BoilerModel, foreignKey *BoilerField) string {
+	return related.GraphName + "CreateWithout" + foreignKey.RelationshipName + "Input"
+}
@@ -0,0 +1,19 @@
+package internal
+
+// IsManyToMany reports whether the to-many relation goes through a join table, sqlboiler generates the
+// methods which link and unlink the related models only for those
+func IsManyToMany(relation *BoilerField) bool {
+	return relation.IsRelation && relation.IsArray && relation.Relationship != nil && relation.JoinTable != ""
+}
+
+// ManyToManyRelations returns the relations of the model which go through a join table, e.g. the tags
+// of a post through post_tags
+func ManyToManyRelations(model *BoilerModel) []*BoilerField {
+	var a []*BoilerField
+	for _, field := range model.Fields {
+		if IsManyToMany(field) {
+			a = append(a, field)
+		}
+	}
+	return a
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GenerateError is a single failure of a generate run. Line and Column point into Template when
+// it is set, otherwise into File. Model and Field are set when the failure could be traced to them.
+type GenerateError struct {
+	Stage    string `json:"stage,omitempty"`
+	File     string `json:"file,omitempty"`
+	Template string `json:"template,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (e *GenerateError) Error() string {
+	var b strings.Builder
+	location := e.File
+	if e.Template != "" {
+		location = e.Template
+	}
+	if location != "" {
+		b.WriteString(location)
+		if e.Line > 0 {
+			b.WriteString(":" + strconv.Itoa(e.Line))
+			if e.Column > 0 {
+				b.WriteString(":" + strconv.Itoa(e.Column))
+			}
+		}
+		if e.Template != "" && e.File != "" {
+			b.WriteString(" (" + e.File + ")")
+		}
+		b.WriteString(": ")
+	}
+	if e.Model != "" {
+		b.WriteString("model " + e.Model)
+		if e.Field != "" {
+			b.WriteString(", field " + e.Field)
+		}
+		b.WriteString(": ")
+	} else if e.Field != "" {
+		b.WriteString("field " + e.Field + ": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// Report collects the errors of a generate run, the generators keep going after a failing
+// model so a single run reports all of them. It is safe for concurrent use.
+type Report struct {
+	mu     sync.Mutex
+	Errors []*GenerateError
+}
+
+// Add adds the errors to the report
+func (r *Report) Add(errs ...*GenerateError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Errors = append(r.Errors, errs...)
+}
+
+// Err returns the report as an error, or nil when nothing failed
+func (r *Report) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r
+}
+
+func (r *Report) Error() string {
+	lines := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	// template: name:12:5: executing "name" at <.Model.Name>: message
+	templateErrorRe   = regexp.MustCompile(`template: [^:]*:(\d+)(?::(\d+))?: (.*)$`)
+	templateExecRe    = regexp.MustCompile(`^executing "[^"]*" at <([^>]*)>: (.*)$`)
+	templateFieldRe   = regexp.MustCompile(`(?:can't evaluate field|nil pointer evaluating [^ ]*\.)\s*(\w+)`)
+	expressionFieldRe = regexp.MustCompile(`\.(\w+)\W*$`)
+	// file.go:12:5: message, as reported by go/format, go/parser, go/types and goimports
+	positionErrorRe = regexp.MustCompile(`^(?:\w+: )?(?:(.*?\.go):)?(\d+):(\d+): (.*)$`)
+)
+
+// NewTemplateError describes the error of rendering the template to the file for the model, the
+// template line and the evaluated field are parsed from errors of text/template and positions in
+// the rendered go code from errors of the go tooling
+func NewTemplateError(file string, template string, model string, err error) *GenerateError {
+	e := &GenerateError{File: file, Template: template, Model: model, Message: err.Error()}
+
+	if m := templateErrorRe.FindStringSubmatch(e.Message); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Column, _ = strconv.Atoi(m[2])
+		e.Message = m[3]
+		if exec := templateExecRe.FindStringSubmatch(e.Message); exec != nil {
+			e.Message = exec[2]
+			if f := templateFieldRe.FindStringSubmatch(exec[2]); f != nil {
+				e.Field = f[1]
+			} else if f := expressionFieldRe.FindStringSubmatch(exec[1]); f != nil {
+				e.Field = f[1]
+			}
+		}
+		return e
+	}
+
+	if p := parsePosition(e.Message); p != nil {
+		// the position is in the rendered file and not in the template
+		e.Template = ""
+		e.Line, e.Column, e.Message = p.Line, p.Column, p.Message
+		if template != "" {
+			e.Message = fmt.Sprintf("%s (rendered from %s)", p.Message, template)
+		}
+	}
+	return e
+}
+
+// PositionErrors splits the error into a GenerateError per line, lines starting with a go
+// position like the type check errors of gqlgen get their file, line and column
+func PositionErrors(err error) []*GenerateError {
+	var errs []*GenerateError
+	for _, line := range strings.Split(strings.TrimSpace(err.Error()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// gqlgen prefixes the first package error with the names of the failing steps
+		if i := strings.Index(line, "packages.Load: "); i >= 0 {
+			line = line[i+len("packages.Load: "):]
+		}
+		if p := parsePosition(line); p != nil {
+			errs = append(errs, p)
+			continue
+		}
+		errs = append(errs, &GenerateError{Message: line})
+	}
+	return errs
+}
+
+func parsePosition(s string) *GenerateError {
+	m := positionErrorRe.FindStringSubmatch(s)
+	if m == nil {
+		return nil
+	}
+	line, _ := strconv.Atoi(m[2])
+	column, _ := strconv.Atoi(m[3])
+	return &GenerateError{File: m[1], Line: line, Column: column, Message: m[4]}
+}
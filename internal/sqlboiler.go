@@ -19,9 +19,42 @@ type BoilerModel struct {
 	Name               string
 	TableName          string
 	PluralName         string
+	GraphName          string
+	GraphPluralName    string
 	Fields             []*BoilerField
 	Enums              []*BoilerEnum
 	HasPrimaryStringID bool
+	// UniqueKeys are the unique constraints and unique indexes of the table besides the primary key
+	UniqueKeys []*BoilerUniqueKey
+}
+
+// CanSoftDelete reports whether sqlboiler soft deletes the rows of the model when soft deletes are added,
+// it does so for tables with a nullable deleted_at column
+func (m *BoilerModel) CanSoftDelete() bool {
+	field := findBoilerField(m.Fields, "DeletedAt")
+	return field != nil && field.Type == "null.Time"
+}
+
+// PrimaryKeyType is the go type of the ID field of the model
+func (m *BoilerModel) PrimaryKeyType() string {
+	if field := findBoilerField(m.Fields, "ID"); field != nil {
+		return field.Type
+	}
+	return ""
+}
+
+// BoilerUniqueKey are the fields of a unique constraint or unique index
+type BoilerUniqueKey struct {
+	Fields []*BoilerField
+}
+
+// ConflictTargetValue is the value of the key in the {Model}ConflictTarget enum, e.g. POST_ID_TAG_ID
+func (k *BoilerUniqueKey) ConflictTargetValue() string {
+	names := make([]string, len(k.Fields))
+	for i, f := range k.Fields {
+		names[i] = strcase.ToScreamingSnake(ToGraphQLName(f.Name))
+	}
+	return strings.Join(names, "_")
 }
 
 type BoilerField struct {
@@ -36,6 +69,9 @@ type BoilerField struct {
 	Enum             BoilerEnum
 	RelationshipName string
 	Relationship     *BoilerModel
+	// JoinTable is the join table of a to-many relation which goes through one, e.g. post_tags for the
+	// tags of a post
+	JoinTable string
 }
 
 type BoilerEnum struct {
@@ -60,6 +96,8 @@ func GetBoilerModels(dir string) ([]*BoilerModel, []*BoilerEnum) { //nolint:goco
 	boilerTypeMap, _, boilerTypeOrder := parseBoilerFile(dir)
 	boilerTypes := getSortedBoilerTypes(boilerTypeMap, boilerTypeOrder)
 	tableNames := parseTableNames(dir)
+	uniqueKeys := parseUniqueKeys(dir)
+	joinTables := parseJoinTables(dir)
 	enums := parseEnums(dir)
 
 	// sortedModelNames is needed to get the right order back of the models since we want the same order every time
@@ -153,9 +191,12 @@ func GetBoilerModels(dir string) ([]*BoilerModel, []*BoilerEnum) { //nolint:goco
 			Name:               modelName,
 			TableName:          tableName,
 			PluralName:         Plural(modelName),
+			GraphName:          modelName,
+			GraphPluralName:    Plural(modelName),
 			Fields:             fields,
 			Enums:              filterEnumsByModelName(enums, modelName),
 			HasPrimaryStringID: hasPrimaryStringID,
+			UniqueKeys:         findUniqueKeys(uniqueKeys, tableName, fields),
 		}
 	}
 
@@ -179,6 +220,11 @@ func GetBoilerModels(dir string) ([]*BoilerModel, []*BoilerEnum) { //nolint:goco
 	}
 	for _, model := range models {
 		for _, field := range model.Fields {
+			if field.IsRelation && field.IsArray && field.Relationship != nil &&
+				HasManyForeignKey(model, field) == nil {
+				field.JoinTable = findJoinTable(joinTables, model.TableName, field.Relationship.TableName)
+			}
+
 			enumForField := getEnumByModelNameAndFieldName(enums, model.Name, field.Name)
 			if enumForField != nil {
 				field.IsEnum = true
@@ -198,6 +244,54 @@ func GetBoilerModels(dir string) ([]*BoilerModel, []*BoilerEnum) { //nolint:goco
 	return models, enums
 }
 
+// LoadBoilerModels parses the sqlboiler models and applies the table renames of the config
+func LoadBoilerModels(cfg *Config) ([]*BoilerModel, []*BoilerEnum) {
+	models, enums := GetBoilerModels(cfg.Model.DirName)
+	for _, model := range models {
+		table := cfg.Tables.Get(model.TableName)
+		if table.Name != "" {
+			model.GraphName = table.Name
+			model.GraphPluralName = Plural(table.Name)
+		}
+		model.UniqueKeys = withoutHiddenColumns(model.UniqueKeys, table)
+	}
+	// the join relationships of the federation config go through a join table as well
+	if cfg.Federation.JoinRelationships != nil {
+		for _, model := range models {
+			for _, field := range model.Fields {
+				if !field.IsRelation || !field.IsArray || field.Relationship == nil || field.JoinTable != "" ||
+					HasManyForeignKey(model, field) != nil {
+					continue
+				}
+				for _, r := range *cfg.Federation.JoinRelationships {
+					if (sameDBName(r.From, model.TableName) && sameDBName(r.To, field.Relationship.TableName)) ||
+						(sameDBName(r.To, model.TableName) && sameDBName(r.From, field.Relationship.TableName)) {
+						field.JoinTable = r.Via
+						break
+					}
+				}
+			}
+		}
+	}
+	return models, enums
+}
+
+// withoutHiddenColumns leaves out the unique keys with a hidden column, these can't be given in the
+// input of an upsert
+func withoutHiddenColumns(keys []*BoilerUniqueKey, table TableConfig) []*BoilerUniqueKey {
+	var a []*BoilerUniqueKey
+	for _, key := range keys {
+		hidden := false
+		for _, f := range key.Fields {
+			hidden = hidden || table.Column(f.Name).Hidden
+		}
+		if !hidden {
+			a = append(a, key)
+		}
+	}
+	return a
+}
+
 func getEnumByModelNameAndFieldName(enums []*BoilerEnum, modelName string, fieldName string) *BoilerEnum {
 	for _, e := range enums {
 		if e.ModelName == modelName && e.ModelFieldKey == fieldName {
@@ -251,6 +345,37 @@ func FindBoilerModel(models []*BoilerModel, modelName string) *BoilerModel {
 	return nil
 }
 
+// FindBoilerModelByGraphName finds the model by the name of its graphql type
+func FindBoilerModelByGraphName(models []*BoilerModel, graphName string) *BoilerModel {
+	for _, m := range models {
+		if m.GraphName == graphName {
+			return m
+		}
+	}
+	return nil
+}
+
+// ToGraphQLName returns the graphql field name of a sqlboiler field name
+func ToGraphQLName(fieldName string) string {
+	graphqlName := fieldName
+
+	// Golang ID to Id the right way
+	// Primary key
+	if graphqlName == "ID" {
+		graphqlName = "id"
+	}
+
+	if graphqlName == "URL" {
+		graphqlName = "url"
+	}
+
+	// e.g. OrganizationID, TODO: more robust solution?
+	graphqlName = strings.Replace(graphqlName, "ID", "Id", -1)
+	graphqlName = strings.Replace(graphqlName, "URL", "Url", -1)
+
+	return strcase.ToLowerCamel(graphqlName)
+}
+
 func isRequired(boilerType string) bool {
 	if strings.HasPrefix(boilerType, "null.") || strings.HasPrefix(boilerType, "*") {
 		return false
@@ -327,6 +452,111 @@ func parseTableNames(dir string) []string {
 	return tableNames
 }
 
+// UniqueKeysFileName is the file next to the models with the unique keys of the tables, sqlboiler does
+// not keep them in the models so it's written after running sqlboiler
+const UniqueKeysFileName = "sinatra_unique_keys.go"
+
+var uniqueKeyRegex = regexp.MustCompile(`\{Table: "([^"]+)", Columns: \[\]string\{([^}]*)\}\}`) //nolint:gochecknoglobals
+
+type uniqueKey struct {
+	table   string
+	columns []string
+}
+
+func parseUniqueKeys(dir string) []*uniqueKey {
+	dir, err := filepath.Abs(dir)
+	errMessage := "could not open unique keys file, no upsert mutations are generated"
+	if err != nil {
+		log.Warn().Err(err).Msg(errMessage)
+		return nil
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, UniqueKeysFileName))
+	if err != nil {
+		log.Warn().Err(err).Msg(errMessage)
+		return nil
+	}
+	matches := uniqueKeyRegex.FindAllStringSubmatch(string(content), -1)
+	keys := make([]*uniqueKey, len(matches))
+	for i, match := range matches {
+		key := &uniqueKey{table: match[1]}
+		for _, column := range strings.Split(match[2], ",") {
+			key.columns = append(key.columns, strings.Trim(strings.TrimSpace(column), `"`))
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// findUniqueKeys returns the unique keys of the table, keys with a column which is not a field of the
+// model, like a blacklisted one, are left out
+func findUniqueKeys(keys []*uniqueKey, tableName string, fields []*BoilerField) []*BoilerUniqueKey {
+	var a []*BoilerUniqueKey
+	for _, key := range keys {
+		if !sameDBName(key.table, tableName) {
+			continue
+		}
+		k := &BoilerUniqueKey{}
+		for _, column := range key.columns {
+			for _, field := range fields {
+				if sameDBName(column, field.Name) {
+					k.Fields = append(k.Fields, field)
+					break
+				}
+			}
+		}
+		if len(k.Fields) == len(key.columns) {
+			a = append(a, k)
+		}
+	}
+	return a
+}
+
+// JoinTablesFileName is the file next to the models with the to-many relationships which go through a
+// join table, it's written after running sqlboiler like the unique keys
+const JoinTablesFileName = "sinatra_join_tables.go"
+
+// InsertHooksFileName is the file next to the models with the methods which run the insert hooks of the
+// models, the batch creates run them for the rows of a multi-row insert
+const InsertHooksFileName = "sinatra_insert_hooks.go"
+
+var joinTableRegex = regexp.MustCompile(`\{Table: "([^"]+)", ForeignTable: "([^"]+)", JoinTable: "([^"]+)"\}`) //nolint:gochecknoglobals
+
+type joinTable struct {
+	table        string
+	foreignTable string
+	joinTable    string
+}
+
+func parseJoinTables(dir string) []*joinTable {
+	dir, err := filepath.Abs(dir)
+	errMessage := "could not open join tables file, no many-to-many mutations are generated"
+	if err != nil {
+		log.Warn().Err(err).Msg(errMessage)
+		return nil
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, JoinTablesFileName))
+	if err != nil {
+		log.Warn().Err(err).Msg(errMessage)
+		return nil
+	}
+	matches := joinTableRegex.FindAllStringSubmatch(string(content), -1)
+	tables := make([]*joinTable, len(matches))
+	for i, match := range matches {
+		tables[i] = &joinTable{table: match[1], foreignTable: match[2], joinTable: match[3]}
+	}
+	return tables
+}
+
+// findJoinTable returns the join table between the tables, or an empty string when they are not joined
+func findJoinTable(tables []*joinTable, tableName string, foreignTableName string) string {
+	for _, t := range tables {
+		if sameDBName(t.table, tableName) && sameDBName(t.foreignTable, foreignTableName) {
+			return t.joinTable
+		}
+	}
+	return ""
+}
+
 var (
 	enumRegex       = regexp.MustCompile(`// Enum values for (\w+).(\w+)\nconst\s\(\n(:?(.|\n)*?)\n\)`) //nolint:gochecknoglobals
 	enumValuesRegex = regexp.MustCompile(`\s(\w+)\s*=\s*"(\w+)"`)                                       //nolint:gochecknoglobals
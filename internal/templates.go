@@ -9,9 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"text/template"
 
@@ -24,6 +22,7 @@ import (
 	"golang.org/x/tools/imports"
 
 	gqlgenTemplates "github.com/99designs/gqlgen/codegen/templates"
+	"github.com/frankie-seb/sinatra/templates"
 )
 
 type Options struct {
@@ -43,6 +42,11 @@ type Options struct {
 	UserDefinedFunctions []string
 	// Data will be passed to the template execution.
 	Data interface{}
+	// Cache skips rendering, formatting and writing the file when its inputs are the same as last time
+	Cache *Cache
+	// Fingerprint is a hash over the inputs Data is built from. Together with the template and the user
+	// defined functions it decides whether the file is up to date, files without one are always written.
+	Fingerprint string
 }
 
 func init() { // nolint:gochecknoinits
@@ -51,26 +55,62 @@ func init() { // nolint:gochecknoinits
 	strcase.ConfigureAcronym("URL", "url")
 }
 
-func GetTemplateContent(filename string) (string, error) {
-	// load path relative to calling source file
-	_, callerFile, _, _ := runtime.Caller(0) //nolint:dogsled
-	rootDir := filepath.Dir(callerFile)
-	absRootDir := rootDir[:len(rootDir)-8]
-	content, err := ioutil.ReadFile(path.Join(absRootDir, "templates", filename))
+// GetTemplateContent returns the template with the given filename, the first override dir of the
+// config containing it wins over the embedded default template
+func GetTemplateContent(cfg *Config, filename string) (string, error) {
+	if cfg != nil {
+		for _, dir := range cfg.Templates.Dirs {
+			content, err := ioutil.ReadFile(filepath.Join(dir, filename))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return "", fmt.Errorf("could not read template file: %v", err)
+			}
+			return string(content), nil
+		}
+	}
+
+	content, err := templates.FS.ReadFile(filename)
 	if err != nil {
 		return "", fmt.Errorf("could not read template file: %v", err)
 	}
 	return string(content), nil
 }
 
+// WriteTemplateFile renders the template to the file. When rendering fails the file is left untouched,
+// when the rendered code is invalid it is written unformatted so the reported positions can be looked up.
 func WriteTemplateFile(fileName string, cfg Options) error {
-	content, contentError := GetConfigTemplateContent(cfg)
-	importFixedContent, importsError := imports.Process(fileName, []byte(content), nil)
+	var fingerprint string
+	if cfg.Fingerprint != "" {
+		fingerprint = Fingerprint(cfg.Fingerprint, cfg.Template, cfg.PackageName, strings.Join(cfg.UserDefinedFunctions, ","))
+		if cfg.Cache.Unchanged(fileName, fingerprint) {
+			return nil
+		}
+	}
+
+	content, renderErr := GetConfigTemplateContent(cfg)
+	if renderErr != nil && content == "" {
+		return renderErr
+	}
+
+	// the configured directory may be nested and not exist yet
+	if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+		return fmt.Errorf("could not create directory for %v: %v", fileName, err)
+	}
+	if renderErr != nil {
+		return writeInvalidContent(fileName, content, renderErr)
+	}
+
+	importFixedContent, err := imports.Process(fileName, []byte(content), nil)
+	if err != nil {
+		return writeInvalidContent(fileName, content, err)
+	}
 
 	fSet := token.NewFileSet()
-	node, err := decorator.ParseFile(fSet, "src.go", string(importFixedContent), parser.ParseComments)
+	node, err := decorator.ParseFile(fSet, fileName, string(importFixedContent), parser.ParseComments)
 	if err != nil {
-		log.Error().Err(err).Msg("could not parse golang file")
+		return writeInvalidContent(fileName, string(importFixedContent), err)
 	}
 
 	dst.Inspect(node, func(n dst.Node) bool {
@@ -83,7 +123,10 @@ func WriteTemplateFile(fileName string, cfg Options) error {
 	})
 
 	// write new ast to file
-	f, writeError := os.Create(fileName)
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("could not write %v: %v", fileName, err)
+	}
 	defer func() {
 		if err := f.Close(); err != nil {
 			log.Error().Err(err).Str("fileName", fileName).Msg("could not close file")
@@ -94,19 +137,25 @@ func WriteTemplateFile(fileName string, cfg Options) error {
 		return fmt.Errorf("errors while printing template to %v  %v", fileName, err)
 	}
 
-	if contentError != nil || writeError != nil || importsError != nil {
-		return fmt.Errorf(
-			"errors while writing template to %v writeError: %v, contentError: %v, importError: %v",
-			fileName, writeError, contentError, importsError)
-	}
 	_, err = exec.Command("go", "fmt", fileName).Output()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error formatting the docs: ", fileName)
 	}
 
+	if fingerprint != "" {
+		cfg.Cache.Set(fileName, fingerprint)
+	}
 	return nil
 }
 
+// writeInvalidContent writes the rendered code which could not be formatted or parsed and returns the cause
+func writeInvalidContent(fileName string, content string, cause error) error {
+	if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+		log.Error().Err(err).Str("fileName", fileName).Msg("could not write invalid content")
+	}
+	return cause
+}
+
 func GetConfigTemplateContent(cfg Options) (string, error) {
 	tpl, err := template.New("").Funcs(template.FuncMap{
 		"go":      gqlgenTemplates.ToGo,
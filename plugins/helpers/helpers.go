@@ -3,6 +3,7 @@ package helpers
 import (
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -22,15 +23,17 @@ func init() {
 	pathRegex = regexp.MustCompile(`src/(.*)`)
 }
 
-func NewHelperPlugin(cfg *internal.Config) plugin.Plugin {
+func NewHelperPlugin(cfg *internal.Config, cache *internal.Cache) plugin.Plugin {
 	return &HelperPlugin{
 		cfg:            cfg,
+		cache:          cache,
 		rootImportPath: internal.GetRootImportPath(),
 	}
 }
 
 type HelperPlugin struct {
 	cfg            *internal.Config
+	cache          *internal.Cache
 	rootImportPath string
 }
 
@@ -43,11 +46,14 @@ type ModelBuild struct {
 	DbModels    internal.DirConfig
 	GraphModels internal.DirConfig
 	PackageName string
-	Federation  FederationConfig
-	Interfaces  []*Interface
-	Models      []*internal.Model
-	Enums       []*internal.Enum
-	Scalars     []string
+	DBDriver    string
+	// AutoTimestamps sets created at and updated at on insert like sqlboiler does
+	AutoTimestamps bool
+	Federation     FederationConfig
+	Interfaces     []*Interface
+	Models         []*internal.Model
+	Enums          []*internal.Enum
+	Scalars        []string
 }
 
 func (t ModelBuild) Imports() []internal.Import {
@@ -102,7 +108,9 @@ func (m *HelperPlugin) MutateConfig(originalCfg *config.Config) error {
 			Directory:   path.Join(m.rootImportPath, m.cfg.Graph.DirName),
 			PackageName: m.cfg.Graph.Package,
 		},
-		PackageName: m.cfg.Helper.Package,
+		PackageName:    m.cfg.Helper.Package,
+		DBDriver:       m.cfg.Database.DBDriver,
+		AutoTimestamps: !m.cfg.Database.NoAutoTimestamps,
 		Federation: FederationConfig{
 			Activate: m.cfg.Federation.Activate,
 			Schema:   m.cfg.Database.Schema,
@@ -116,7 +124,7 @@ func (m *HelperPlugin) MutateConfig(originalCfg *config.Config) error {
 	// log.Debug().Msg("[customization] looking for *_customized files")
 
 	// log.Debug().Msg("[convert] get boiler models")
-	boilerModels, boilerEnums := internal.GetBoilerModels(m.cfg.Model.DirName)
+	boilerModels, boilerEnums := internal.LoadBoilerModels(m.cfg)
 
 	// log.Debug().Msg("[convert] get extra's from schema")
 	interfaces, enums, scalars := getExtrasFromSchema(cfg.Schema, boilerEnums)
@@ -140,7 +148,7 @@ func (m *HelperPlugin) MutateConfig(originalCfg *config.Config) error {
 	// Manage join relations subqueries
 	for _, model := range models {
 		var js []internal.JoinRelationship
-		if model.IsWhere {
+		if model.IsWhere && m.cfg.Federation.JoinRelationships != nil {
 			for _, v := range *m.cfg.Federation.JoinRelationships {
 				if v.To == model.BoilerModel.TableName {
 					// Convert to Snake Case
@@ -170,42 +178,80 @@ func (m *HelperPlugin) MutateConfig(originalCfg *config.Config) error {
 		}
 	}
 
-	filesToGenerate := []string{
-		"base.go",
-		"lib.go",
-		"common_filter.go",
-		"preload.go",
-	}
+	filesToGenerate := internal.HelperFiles
 
 	// We get all function names from helper repository to check if any customizations are available
 	// we ignore the files we generated by this plugin
-	userDefinedFunctions, err := internal.GetFunctionNamesFromDir(m.cfg.Helper.Package, filesToGenerate)
+	userDefinedFunctions, err := internal.GetFunctionNamesFromDir(m.cfg.Helper.DirName, filesToGenerate)
 	if err != nil {
 		log.Err(err).Msg("could not parse user defined functions")
 	}
 
+	// every helper file is rendered from all models, the graphql types of the schema and the config
+	fingerprint := internal.Fingerprint(
+		internal.FingerprintJSON(m.cfg),
+		internal.BoilerModelsFingerprint(boilerModels, boilerModels),
+		internal.SchemaFingerprint(cfg.Schema),
+		m.rootImportPath,
+	)
+
+	report := &internal.Report{}
 	for _, fileName := range filesToGenerate {
 		templateName := fileName + "tpl"
+		filePath := filepath.Join(m.cfg.Helper.DirName, fileName)
 
-		templateContent, err := internal.GetTemplateContent(templateName)
+		templateContent, err := internal.GetTemplateContent(m.cfg, templateName)
 		if err != nil {
 			log.Err(err).Msg("error when reading " + templateName)
+			report.Add(&internal.GenerateError{File: filePath, Template: templateName, Message: err.Error()})
 			continue
 		}
 
 		if renderError := internal.WriteTemplateFile(
-			m.cfg.Helper.DirName+"/"+fileName,
+			filePath,
 			internal.Options{
 				Template:             templateContent,
 				PackageName:          m.cfg.Helper.Package,
 				Data:                 b,
 				UserDefinedFunctions: userDefinedFunctions,
+				Cache:                m.cache,
+				Fingerprint:          fingerprint,
 			}); renderError != nil {
 			log.Err(renderError).Msg("error while rendering " + templateName)
+			report.Add(m.templateErrors(b, filePath, templateName, templateContent, renderError)...)
+		}
+	}
+
+	return report.Err()
+}
+
+// templateErrors traces the render error back to the models, the template is rendered once per model
+// and every model failing at the same template position is reported
+func (m *HelperPlugin) templateErrors(b *ModelBuild, fileName, templateName, templateContent string, renderError error) []*internal.GenerateError {
+	e := internal.NewTemplateError(fileName, templateName, "", renderError)
+	if e.Template == "" {
+		// the rendered code is invalid, its position says more than the model
+		return []*internal.GenerateError{e}
+	}
+
+	var errs []*internal.GenerateError
+	for _, model := range b.Models {
+		single := *b
+		single.Models = []*internal.Model{model}
+		_, err := internal.GetConfigTemplateContent(internal.Options{Template: templateContent, Data: &single})
+		if err == nil {
+			continue
+		}
+		if me := internal.NewTemplateError(fileName, templateName, model.Name, err); me.Line == e.Line && me.Column == e.Column {
+			errs = append(errs, me)
 		}
 	}
 
-	return nil
+	// an error of every model is an error of the template itself
+	if len(errs) == 0 || len(errs) == len(b.Models) {
+		return []*internal.GenerateError{e}
+	}
+	return errs
 }
 
 func enumsWithout(enums []*internal.Enum, skip []string) []*internal.Enum {
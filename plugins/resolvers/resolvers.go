@@ -7,7 +7,9 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/frankie-seb/sinatra/internal"
 
@@ -17,26 +19,37 @@ import (
 
 	"github.com/99designs/gqlgen/codegen"
 	"github.com/99designs/gqlgen/plugin"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
-func NewResolverPlugin(cfg *internal.Config) plugin.Plugin {
+func NewResolverPlugin(cfg *internal.Config, cache *internal.Cache, scopes ...*AuthorizationScope) plugin.Plugin {
 	return &ResolverPlugin{
-		cfg:            cfg,
-		rootImportPath: internal.GetRootImportPath(),
+		cfg:                 cfg,
+		cache:               cache,
+		authorizationScopes: scopes,
+		rootImportPath:      internal.GetRootImportPath(),
 	}
 }
 
+// AuthorizationScope adds a where clause to the queries and sets the column in the inputs of the generated
+// resolvers, e.g. to limit everything to the organization of the user. The value is returned by the function
+// ScopeResolverName(ctx) of the package at ImportPath.
 type AuthorizationScope struct {
 	ImportPath        string
 	ImportAlias       string
 	ScopeResolverName string
 	BoilerColumnName  string
-	AddHook           func(model *internal.BoilerModel, resolver *Resolver, templateKey string) bool
+	// AddHook decides per model and resolver whether the scope is applied, when nil it is applied to every
+	// model with the BoilerColumnName column. The resolver is nil for the nodes query (templateKey nodesWhere)
+	// and the nested creates of to-many relations (templateKey createNestedInput).
+	AddHook func(model *internal.BoilerModel, resolver *Resolver, templateKey string) bool
 }
 
 type ResolverPlugin struct {
-	cfg            *internal.Config
-	rootImportPath string
+	cfg                 *internal.Config
+	cache               *internal.Cache
+	authorizationScopes []*AuthorizationScope
+	rootImportPath      string
 }
 
 var _ plugin.CodeGenerator = &ResolverPlugin{}
@@ -49,7 +62,7 @@ func (m *ResolverPlugin) GenerateCode(data *codegen.Data) error {
 	if !data.Config.Resolver.IsDefined() {
 		return nil
 	}
-	boilerModels, _ := internal.GetBoilerModels(m.cfg.Model.DirName)
+	boilerModels, _ := internal.LoadBoilerModels(m.cfg)
 	models := internal.GetModelsWithInformation(m.cfg.Model.Package, nil, data.Config, boilerModels, nil, nil)
 	return m.generatePerSchema(data, models, boilerModels)
 }
@@ -72,7 +85,7 @@ func groupByBoilerModelName(list []*internal.Model) [][]*internal.Model {
 	return r
 }
 
-func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*internal.Model, _ []*internal.BoilerModel) error {
+func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*internal.Model, boilerModels []*internal.BoilerModel) error {
 	file := File{}
 
 	file.Imports = append(file.Imports, internal.Import{
@@ -92,7 +105,7 @@ func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*interna
 
 	// Get model resolver template
 	templateName := "resolver.gotpl"
-	templateContent, err := internal.GetTemplateContent(templateName)
+	templateContent, err := internal.GetTemplateContent(m.cfg, templateName)
 	if err != nil {
 		log.Err(err).Msg("error when reading " + templateName)
 		return err
@@ -100,7 +113,7 @@ func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*interna
 
 	// Get common resolver template
 	commonTemplateName := "common_resolver.gotpl"
-	commonTemplateContent, err := internal.GetTemplateContent(commonTemplateName)
+	commonTemplateContent, err := internal.GetTemplateContent(m.cfg, commonTemplateName)
 	if err != nil {
 		log.Err(err).Msg("error when reading " + templateName)
 		return err
@@ -111,6 +124,8 @@ func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*interna
 	if err != nil {
 		log.Err(err).Msg("error when reading " + templateName)
 	}
+	dir = filepath.Join(dir, m.cfg.Resolver.DirName)
+	report := &internal.Report{}
 
 	// Sort the models
 	rMod := groupByBoilerModelName(models)
@@ -122,37 +137,60 @@ func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*interna
 		extendedFiles = append(extendedFiles, strings.ToLower(internal.GetFirstWord(v[0].Name))+"_gen.go")
 	}
 
-	extendedFunctions, err := internal.GetResolverFunctionNamesFromDir("resolvers", extendedFiles)
+	extendedFunctions, err := internal.GetResolverFunctionNamesFromDir(dir, extendedFiles)
 	if err != nil {
 		log.Err(err).Msg("could not parse user defined functions in resolver")
 	}
 
 	resolverBuild := &ResolverBuild{
-		File:              &file,
-		PackageName:       data.Config.Resolver.Package,
-		ResolverType:      data.Config.Resolver.Type,
-		HasRoot:           true,
-		IsFederatedServer: data.Config.Federation.IsDefined(),
-		Models:            models,
-		SoftDelete:        m.cfg.Database.AddSoftDeletes,
+		File:                &file,
+		PackageName:         data.Config.Resolver.Package,
+		ResolverType:        data.Config.Resolver.Type,
+		HasRoot:             true,
+		IsFederatedServer:   data.Config.Federation.IsDefined(),
+		Models:              models,
+		AuthorizationScopes: m.getAuthorizationScopes(),
+		SoftDelete:          m.cfg.Database.AddSoftDeletes,
+		DBDriver:            m.cfg.Database.DBDriver,
+	}
+	for _, scope := range resolverBuild.AuthorizationScopes {
+		file.Imports = append(file.Imports, internal.Import{
+			Alias:      scope.ImportAlias,
+			ImportPath: scope.ImportPath,
+		})
 	}
 
+	// Add in helper import, the common resolver loads the nodes with them
+	file.Imports = append(file.Imports, internal.Import{
+		Alias:      ".",
+		ImportPath: path.Join(m.rootImportPath, m.cfg.Helper.DirName),
+	})
+
+	// the resolvers are rendered from the config, the authorization scopes and the imports, besides the
+	// models. The hooks of the scopes are code, only whether they are set is part of the fingerprint.
+	base := internal.Fingerprint(
+		internal.FingerprintJSON(m.cfg),
+		internal.FingerprintJSON(file.Imports),
+		scopesFingerprint(resolverBuild.AuthorizationScopes),
+		resolverBuild.ResolverType,
+		strconv.FormatBool(resolverBuild.IsFederatedServer),
+	)
+
 	// Write Common Resolver
-	if err := internal.WriteTemplateFile(dir+"/resolvers/resolver.go", internal.Options{
+	commonFileName := filepath.Join(dir, "resolver.go")
+	if err := internal.WriteTemplateFile(commonFileName, internal.Options{
 		Template:             commonTemplateContent,
 		PackageName:          data.Config.Resolver.Package,
 		Data:                 resolverBuild,
 		UserDefinedFunctions: extendedFunctions,
+		Cache:                m.cache,
+		// the common resolver loads the nodes of all models
+		Fingerprint: internal.Fingerprint(base, groupFingerprint(data.Schema, models, boilerModels, nil)),
 	}); err != nil {
 		log.Err(err).Msg("Could not write resolver")
+		report.Add(internal.NewTemplateError(m.relativePath(commonFileName), commonTemplateName, "", err))
 	}
 
-	// Add in helper import
-	file.Imports = append(file.Imports, internal.Import{
-		Alias:      ".",
-		ImportPath: path.Join(m.rootImportPath, m.cfg.Helper.DirName),
-	})
-
 	// Run the resolver write process
 	for _, v := range rMod {
 		file.Resolvers = []*Resolver{}
@@ -171,11 +209,18 @@ func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*interna
 					"create": "",
 					"update": "",
 					"delete": "",
+					"upsert": "",
 				}
 
 				for s, r := range replace {
 					n = strings.Replace(n, s, r, -1)
 				}
+				// e.g. addPostTags belongs to the posts and restoreUser to the users
+				for _, prefix := range []string{"add", "remove", "set", "restore", "permanentlyDelete"} {
+					if len(n) > len(prefix) && strings.HasPrefix(n, prefix) && unicode.IsUpper(rune(n[len(prefix)])) {
+						n = strings.TrimPrefix(n, prefix)
+					}
+				}
 
 				if strings.EqualFold(internal.GetFirstWord(v[0].Name), internal.GetFirstWord(n)) || strings.EqualFold(internal.Plural(internal.GetFirstWord(v[0].Name)), internal.GetFirstWord(n)) {
 					resolver := &Resolver{
@@ -185,6 +230,9 @@ func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*interna
 					}
 					enhanceResolver(resolver, models)
 					if resolver.Model.BoilerModel != nil && resolver.Model.BoilerModel.Name != "" {
+						if resolver.IsAggregate {
+							resolver.AggregateColumns = internal.GetAggregateColumns(m.cfg, resolver.Model.BoilerModel)
+						}
 						file.Resolvers = append(file.Resolvers, resolver)
 					}
 				}
@@ -194,23 +242,85 @@ func (m *ResolverPlugin) generatePerSchema(data *codegen.Data, models []*interna
 
 		resolverBuild.Models = v
 
-		if err := internal.WriteTemplateFile(dir+"/resolvers/"+strings.ToLower(internal.GetFirstWord(v[0].Name))+"_gen.go", internal.Options{
+		// keep going with the other models, the report names every one that failed
+		fileName := filepath.Join(dir, strings.ToLower(internal.GetFirstWord(v[0].Name))+"_gen.go")
+		if err := internal.WriteTemplateFile(fileName, internal.Options{
 			Template:             templateContent,
 			PackageName:          data.Config.Resolver.Package,
 			Data:                 resolverBuild,
 			UserDefinedFunctions: extendedFunctions,
+			Cache:                m.cache,
+			Fingerprint:          internal.Fingerprint(base, groupFingerprint(data.Schema, v, boilerModels, file.Resolvers)),
 		}); err != nil {
 			log.Err(err).Msg("Could not write resolver")
+			report.Add(internal.NewTemplateError(m.relativePath(fileName), templateName, internal.GetFirstWord(v[0].Name), err))
 		}
 	}
 
 	// Replace text in resolvers
-	err = filepath.Walk(dir+"/resolvers", ReplaceGeneratedText)
+	err = filepath.Walk(dir, ReplaceGeneratedText)
 	if err != nil {
-		panic(err)
+		report.Add(&internal.GenerateError{File: m.cfg.Resolver.DirName, Message: err.Error()})
 	}
 
-	return nil
+	return report.Err()
+}
+
+// groupFingerprint returns a hash over what the resolvers of a group are rendered from: the graphql types
+// of its models and of the models related to them, their sqlboiler metadata and the resolved fields
+func groupFingerprint(
+	schema *ast.Schema,
+	models []*internal.Model,
+	boilerModels []*internal.BoilerModel,
+	resolvers []*Resolver,
+) string {
+	var own []*internal.BoilerModel
+	for _, model := range models {
+		if model.BoilerModel != nil {
+			own = append(own, model.BoilerModel)
+		}
+	}
+
+	parts := []string{internal.BoilerModelsFingerprint(own, boilerModels)}
+	for _, model := range models {
+		parts = append(parts, internal.DefinitionFingerprint(schema.Types[model.Name]))
+		for _, f := range model.Fields {
+			if f.Relationship != nil {
+				parts = append(parts, internal.DefinitionFingerprint(schema.Types[f.Relationship.Name]))
+			}
+		}
+	}
+	for _, r := range resolvers {
+		parts = append(parts, r.Object.Name, r.Field.Name, r.Field.Type.String(), r.Field.GoFieldName)
+		for _, arg := range r.Field.Args {
+			parts = append(parts, arg.Name, arg.Type.String())
+		}
+	}
+	return internal.Fingerprint(parts...)
+}
+
+func scopesFingerprint(scopes []*AuthorizationScope) string {
+	var parts []string
+	for _, scope := range scopes {
+		parts = append(parts,
+			scope.ImportPath,
+			scope.ImportAlias,
+			scope.ScopeResolverName,
+			scope.BoilerColumnName,
+			strconv.FormatBool(scope.AddHook != nil),
+		)
+	}
+	return internal.Fingerprint(parts...)
+}
+
+// relativePath returns the path of the generated file relative to the project root
+func (m *ResolverPlugin) relativePath(fileName string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, fileName); err == nil {
+			return rel
+		}
+	}
+	return fileName
 }
 
 func buildImportPath(rootImportPath, directory string) string {
@@ -221,6 +331,30 @@ func buildImportPath(rootImportPath, directory string) string {
 	return directory
 }
 
+// getAuthorizationScopes returns copies of the scopes with a default AddHook, templates can not call nil functions
+func (m *ResolverPlugin) getAuthorizationScopes() []*AuthorizationScope {
+	scopes := make([]*AuthorizationScope, len(m.authorizationScopes))
+	for i, s := range m.authorizationScopes {
+		scope := *s
+		if scope.AddHook == nil {
+			scope.AddHook = func(model *internal.BoilerModel, _ *Resolver, _ string) bool {
+				return model != nil && hasBoilerField(model, scope.BoilerColumnName)
+			}
+		}
+		scopes[i] = &scope
+	}
+	return scopes
+}
+
+func hasBoilerField(model *internal.BoilerModel, name string) bool {
+	for _, f := range model.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 type ResolverBuild struct {
 	*File
 	HasRoot             bool
@@ -230,6 +364,7 @@ type ResolverBuild struct {
 	Models              []*internal.Model
 	AuthorizationScopes []*AuthorizationScope
 	SoftDelete          bool
+	DBDriver            string
 	TryHook             func(string) bool
 }
 
@@ -256,7 +391,16 @@ type Resolver struct {
 	IsDelete                  bool
 	IsBatchCreate             bool
 	IsBatchUpdate             bool
+	IsBatchUpdateByID         bool
 	IsBatchDelete             bool
+	IsAggregate               bool
+	IsUpsert                  bool
+	IsAddRelation             bool
+	IsRemoveRelation          bool
+	IsSetRelation             bool
+	IsRestore                 bool
+	IsBatchRestore            bool
+	IsPermanentDelete         bool
 	IsIgnore                  bool
 	ResolveOrganizationID     bool // TODO: something more pluggable
 	ResolveUserOrganizationID bool // TODO: something more pluggable
@@ -266,6 +410,10 @@ type Resolver struct {
 	BoilerWhiteList           string
 	PublicErrorKey            string
 	PublicErrorMessage        string
+	// AggregateColumns are set on aggregate resolvers
+	AggregateColumns *internal.AggregateColumns
+	// Relation is the many-to-many relation of the add, remove and set resolvers
+	Relation *internal.BoilerField
 }
 
 func (rb *ResolverBuild) getResolverType(ty string) string {
@@ -300,9 +448,58 @@ func (rb *ResolverBuild) ShortResolverDeclaration(r *Resolver) string {
 	return res
 }
 
+func hasArgument(field *codegen.Field, name string) bool {
+	for _, arg := range field.Args {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func enhanceResolver(r *Resolver, models []*internal.Model) { //nolint:gocyclo
 	nameOfResolver := r.Field.GoFieldName
 
+	// e.g. UserAggregate is a query on User
+	r.IsAggregate = r.Object.Name == "Query" && strings.HasSuffix(nameOfResolver, "Aggregate")
+	if r.IsAggregate {
+		nameOfResolver = strings.TrimSuffix(nameOfResolver, "Aggregate")
+	}
+	// e.g. UpdateUsersByID updates users with an UserUpdateInput per row
+	r.IsBatchUpdateByID = r.Object.Name == "Mutation" && strings.HasPrefix(nameOfResolver, "Update") &&
+		strings.HasSuffix(nameOfResolver, "ByID")
+	if r.IsBatchUpdateByID {
+		nameOfResolver = strings.TrimSuffix(nameOfResolver, "ByID")
+	}
+	// e.g. AddPostTags links tags to a post
+	if r.Object.Name == "Mutation" {
+		if action, model, relation := findManyToMany(models, nameOfResolver); relation != nil {
+			r.IsAddRelation = action == "Add"
+			r.IsRemoveRelation = action == "Remove"
+			r.IsSetRelation = action == "Set"
+			r.Relation = relation
+			nameOfResolver = model.Name
+		}
+	}
+	// e.g. UpsertUser takes the UserCreateInput
+	r.IsUpsert = r.Object.Name == "Mutation" && containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Upsert")
+	if r.IsUpsert {
+		nameOfResolver = "Create" + strings.TrimPrefix(nameOfResolver, "Upsert")
+	}
+
+	// e.g. RestoreUser and RestoreUsers undo soft deletes, PermanentlyDeleteUser deletes for good
+	if r.Object.Name == "Mutation" {
+		r.IsRestore = containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Restore")
+		r.IsBatchRestore = containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Restore")
+		r.IsPermanentDelete = containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "PermanentlyDelete")
+		if r.IsRestore || r.IsBatchRestore {
+			nameOfResolver = "Delete" + strings.TrimPrefix(nameOfResolver, "Restore")
+		}
+		if r.IsPermanentDelete {
+			nameOfResolver = strings.TrimPrefix(nameOfResolver, "Permanently")
+		}
+	}
+
 	// get model names + model convert information
 	modelName, inputModelName := getModelNames(nameOfResolver, false)
 	// modelPluralName, _ := getModelNames(nameOfResolver, true)
@@ -316,23 +513,21 @@ func enhanceResolver(r *Resolver, models []*internal.Model) { //nolint:gocyclo
 
 	switch r.Object.Name {
 	case "Mutation":
-		r.IsCreate = containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Create")
+		r.IsCreate = !r.IsUpsert && containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Create")
 		r.IsUpdate = containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Update")
-		r.IsDelete = containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Delete")
+		r.IsDelete = !r.IsRestore && !r.IsPermanentDelete && containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Delete")
 		r.IsBatchCreate = containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Create")
-		r.IsBatchUpdate = containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Update")
-		r.IsBatchDelete = containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Delete")
+		r.IsBatchUpdate = !r.IsBatchUpdateByID && containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Update")
+		r.IsBatchDelete = !r.IsBatchRestore && containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Delete")
 	case "Query":
 		isPlural := internal.IsPlural(nameOfResolver)
 		if isPlural {
 			r.IsList = isPlural
-			r.IsListBackward = strings.Contains(r.Field.GoFieldName, "first int") &&
-				strings.Contains(r.Field.GoFieldName, "after *string")
-			r.IsListBackward = strings.Contains(r.Field.GoFieldName, "last int") &&
-				strings.Contains(r.Field.GoFieldName, "before *string")
+			r.IsListForward = hasArgument(r.Field, "first") && hasArgument(r.Field, "after")
+			r.IsListBackward = hasArgument(r.Field, "last") && hasArgument(r.Field, "before")
 		}
 
-		r.IsSingle = !r.IsList
+		r.IsSingle = !r.IsList && !r.IsAggregate
 	case "Subscription":
 		// TODO: generate helpers for subscription
 	default:
@@ -345,12 +540,16 @@ func enhanceResolver(r *Resolver, models []*internal.Model) { //nolint:gocyclo
 	lmpName := strcase.ToLowerCamel(model.PluralName)
 	r.PublicErrorKey = "public"
 
-	if (r.IsCreate || r.IsDelete || r.IsUpdate) && strings.HasSuffix(lmName, "Batch") {
+	if (r.IsCreate || r.IsDelete || r.IsUpdate || r.IsUpsert || r.IsRestore || r.IsPermanentDelete) &&
+		strings.HasSuffix(lmName, "Batch") {
 		r.PublicErrorKey += "One"
 	}
 	r.PublicErrorKey += model.Name
 
 	switch {
+	case r.IsAggregate:
+		r.PublicErrorKey += "Aggregate"
+		r.PublicErrorMessage = "could not aggregate " + lmpName
 	case r.IsSingle:
 		r.PublicErrorKey += "Single"
 		r.PublicErrorMessage = "could not get " + lmName
@@ -366,15 +565,39 @@ func enhanceResolver(r *Resolver, models []*internal.Model) { //nolint:gocyclo
 	case r.IsDelete:
 		r.PublicErrorKey += "Delete"
 		r.PublicErrorMessage = "could not delete " + lmName
+	case r.IsUpsert:
+		r.PublicErrorKey += "Upsert"
+		r.PublicErrorMessage = "could not upsert " + lmName
+	case r.IsRestore:
+		r.PublicErrorKey += "Restore"
+		r.PublicErrorMessage = "could not restore " + lmName
+	case r.IsPermanentDelete:
+		r.PublicErrorKey += "PermanentDelete"
+		r.PublicErrorMessage = "could not permanently delete " + lmName
+	case r.IsAddRelation:
+		r.PublicErrorKey += "Add" + r.Relation.Name
+		r.PublicErrorMessage = "could not add " + strcase.ToLowerCamel(r.Relation.Name) + " to " + lmName
+	case r.IsRemoveRelation:
+		r.PublicErrorKey += "Remove" + r.Relation.Name
+		r.PublicErrorMessage = "could not remove " + strcase.ToLowerCamel(r.Relation.Name) + " from " + lmName
+	case r.IsSetRelation:
+		r.PublicErrorKey += "Set" + r.Relation.Name
+		r.PublicErrorMessage = "could not set " + strcase.ToLowerCamel(r.Relation.Name) + " of " + lmName
 	case r.IsBatchCreate:
 		r.PublicErrorKey += "BatchCreate"
 		r.PublicErrorMessage = "could not create " + lmpName
 	case r.IsBatchUpdate:
 		r.PublicErrorKey += "BatchUpdate"
 		r.PublicErrorMessage = "could not update " + lmpName
+	case r.IsBatchUpdateByID:
+		r.PublicErrorKey += "BatchUpdateByID"
+		r.PublicErrorMessage = "could not update " + lmpName
 	case r.IsBatchDelete:
 		r.PublicErrorKey += "BatchDelete"
 		r.PublicErrorMessage = "could not delete " + lmpName
+	case r.IsBatchRestore:
+		r.PublicErrorKey += "BatchRestore"
+		r.PublicErrorMessage = "could not restore " + lmpName
 	}
 
 	r.PublicErrorKey += "Error"
@@ -394,6 +617,28 @@ func findModelOrEmpty(models []*internal.Model, modelName string) internal.Model
 
 var InputTypes = []string{"Create", "Update", "Delete"} //nolint:gochecknoglobals
 
+// findManyToMany returns the action, the model and the many-to-many relation of the resolvers which link
+// and unlink related models e.g. Add, Post and Tags for AddPostTags
+func findManyToMany(models []*internal.Model, name string) (string, *internal.Model, *internal.BoilerField) {
+	for _, action := range []string{"Add", "Remove", "Set"} {
+		if !strings.HasPrefix(name, action) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, action)
+		for _, model := range models {
+			if !model.IsNormal || model.BoilerModel == nil || !strings.HasPrefix(rest, model.Name) {
+				continue
+			}
+			for _, relation := range internal.ManyToManyRelations(model.BoilerModel) {
+				if model.Name+relation.Name == rest {
+					return action, model, relation
+				}
+			}
+		}
+	}
+	return "", nil, nil
+}
+
 func getModelNames(v string, plural bool) (modelName, inputModelName string) {
 	var prefix string
 	var isInputType bool
@@ -447,6 +692,10 @@ func ReplaceGeneratedText(path string, fi os.FileInfo, err error) error {
 
 		newContents := strings.Replace(string(read), "// OVERIDESTART", "/*", -1)
 		newContents2 := strings.Replace(string(newContents), "// OVERIDEEND", "*/", -1)
+		// files without overrides are left untouched, the cache keeps them as they are
+		if newContents2 == string(read) {
+			return nil
+		}
 
 		err = ioutil.WriteFile(path, []byte(newContents2), 0)
 		if err != nil {
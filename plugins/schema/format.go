@@ -0,0 +1,399 @@
+package schema
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/lexer"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// formatSchema parses the graphql sdl and prints it back in a stable format, definitions keep their
+// order and are separated by an empty line, fields and enum values are printed one per line. The #
+// comments are kept, on their own line before the element they preceded or at the end of its line.
+func formatSchema(filename string, content string) (string, error) {
+	src := &ast.Source{Name: filename, Input: content}
+	doc, gqlErr := parser.ParseSchema(src)
+	if gqlErr != nil {
+		return "", gqlErr
+	}
+	c, gqlErr := readComments(src)
+	if gqlErr != nil {
+		return "", gqlErr
+	}
+
+	var blocks []formatBlock
+	for _, def := range doc.Schema {
+		blocks = append(blocks, formatBlock{def.Position, schemaDefinitionFormatter(def, false)})
+	}
+	for _, def := range doc.SchemaExtension {
+		blocks = append(blocks, formatBlock{def.Position, schemaDefinitionFormatter(def, true)})
+	}
+	for _, def := range doc.Directives {
+		def := def
+		blocks = append(blocks, formatBlock{def.Position, func(c *comments, from, to int) string {
+			return formatDirectiveDefinition(c, from, to, def)
+		}})
+	}
+	for _, def := range doc.Definitions {
+		blocks = append(blocks, formatBlock{def.Position, definitionFormatter(def, false)})
+	}
+	for _, def := range doc.Extensions {
+		blocks = append(blocks, formatBlock{def.Position, definitionFormatter(def, true)})
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].position.Start < blocks[j].position.Start })
+
+	// a block runs from its description or keyword up to the start of the next block
+	starts := make([]int, len(blocks)+1)
+	for i, b := range blocks {
+		starts[i] = c.blockStart(b.position)
+	}
+	starts[len(blocks)] = c.eof()
+
+	var a []string
+	for i, b := range blocks {
+		a = append(a, b.format(c, starts[i], starts[i+1]-1))
+	}
+	// the comments at the end of the file
+	w := &SimpleWriter{}
+	c.writeLeading(w, "", c.eof(), c.eof())
+	if w.s.Len() > 0 {
+		a = append(a, strings.TrimSuffix(w.s.String(), lineBreak))
+	}
+	if len(a) == 0 {
+		return "", nil
+	}
+	return strings.Join(a, lineBreak+lineBreak) + lineBreak, nil
+}
+
+var definitionKeywords = map[ast.DefinitionKind]string{
+	ast.Scalar:      "scalar",
+	ast.Object:      "type",
+	ast.Interface:   "interface",
+	ast.Union:       "union",
+	ast.Enum:        "enum",
+	ast.InputObject: "input",
+}
+
+// formatBlock is a top level definition, format prints it with the comments of the tokens from to to
+type formatBlock struct {
+	position *ast.Position
+	format   func(c *comments, from, to int) string
+}
+
+func schemaDefinitionFormatter(def *ast.SchemaDefinition, extend bool) func(c *comments, from, to int) string {
+	return func(c *comments, from, to int) string {
+		return formatSchemaDefinition(c, from, to, def, extend)
+	}
+}
+
+func definitionFormatter(def *ast.Definition, extend bool) func(c *comments, from, to int) string {
+	return func(c *comments, from, to int) string {
+		return formatDefinition(c, from, to, def, extend)
+	}
+}
+
+func formatSchemaDefinition(c *comments, from, to int, def *ast.SchemaDefinition, extend bool) string {
+	head := "schema" + formatDirectives(def.Directives)
+	if extend {
+		head = "extend " + head
+	}
+	positions := make([]*ast.Position, len(def.OperationTypes))
+	lines := make([]string, len(def.OperationTypes))
+	for i, op := range def.OperationTypes {
+		positions[i] = op.Position
+		lines[i] = string(op.Operation) + ": " + op.Type
+	}
+	return formatBody(c, from, to, def.Description, head, positions, func(w *SimpleWriter, i int) {
+		w.tl(lines[i] + c.trailing(c.index(positions[i]), bodyEnd(c, i, positions, to)))
+	})
+}
+
+func formatDirectiveDefinition(c *comments, from, to int, def *ast.DirectiveDefinition) string {
+	v := "directive @" + def.Name + formatArgumentDefinitions(def.Arguments, "")
+	if def.IsRepeatable {
+		v += " repeatable"
+	}
+	locations := make([]string, len(def.Locations))
+	for i, l := range def.Locations {
+		locations[i] = string(l)
+	}
+	v += " on " + strings.Join(locations, " | ")
+	return formatBody(c, from, to, def.Description, v, nil, nil)
+}
+
+// formatBody prints a definition with the element at each of the positions in braces, the comments of
+// the tokens before the first element belong to the head and those of the closing brace to the body
+func formatBody(
+	c *comments,
+	from, to int,
+	description, head string,
+	positions []*ast.Position,
+	element func(w *SimpleWriter, i int),
+) string {
+	w := &SimpleWriter{}
+	headEnd := to
+	if len(positions) > 0 {
+		headEnd = c.index(positions[0]) - 1
+	}
+	c.writeLeading(w, "", from, headEnd)
+	writeDescription(w, "", description)
+	if len(positions) == 0 {
+		w.l(head + c.trailing(from, headEnd))
+		return strings.TrimSuffix(w.s.String(), lineBreak)
+	}
+
+	w.l(head + " {" + c.trailing(from, headEnd))
+	for i := range positions {
+		c.writeLeading(w, indent, c.index(positions[i]), bodyEnd(c, i, positions, to))
+		element(w, i)
+	}
+	// the last token of the block is the closing brace
+	c.writeLeading(w, indent, to, to)
+	w.l("}" + c.trailing(to, to))
+	return strings.TrimSuffix(w.s.String(), lineBreak)
+}
+
+// bodyEnd is the last token of the i-th element of a body, the one before the next element or the
+// closing brace
+func bodyEnd(c *comments, i int, positions []*ast.Position, to int) int {
+	if i+1 < len(positions) {
+		return c.index(positions[i+1]) - 1
+	}
+	return to - 1
+}
+
+func formatDefinition(c *comments, from, to int, def *ast.Definition, extend bool) string {
+	head := definitionKeywords[def.Kind] + " " + def.Name
+	if extend {
+		head = "extend " + head
+	}
+	if len(def.Interfaces) > 0 {
+		head += " implements " + strings.Join(def.Interfaces, " & ")
+	}
+	head += formatDirectives(def.Directives)
+	if len(def.Types) > 0 {
+		head += " = " + strings.Join(def.Types, " | ")
+	}
+
+	var positions []*ast.Position
+	var lines, descriptions []string
+	for _, field := range def.Fields {
+		v := field.Name + formatArgumentDefinitions(field.Arguments, indent) + ": " + field.Type.String()
+		if field.DefaultValue != nil {
+			v += " = " + formatValue(field.DefaultValue)
+		}
+		positions = append(positions, field.Position)
+		lines = append(lines, v+formatDirectives(field.Directives))
+		descriptions = append(descriptions, field.Description)
+	}
+	for _, value := range def.EnumValues {
+		positions = append(positions, value.Position)
+		lines = append(lines, value.Name+formatDirectives(value.Directives))
+		descriptions = append(descriptions, value.Description)
+	}
+
+	return formatBody(c, from, to, def.Description, head, positions, func(w *SimpleWriter, i int) {
+		writeDescription(w, indent, descriptions[i])
+		w.tl(lines[i] + c.trailing(c.index(positions[i]), bodyEnd(c, i, positions, to)))
+	})
+}
+
+// formatArgumentDefinitions prints the arguments on one line, unless one of them has a description
+func formatArgumentDefinitions(args ast.ArgumentDefinitionList, prefix string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	multiline := false
+	for _, arg := range args {
+		if arg.Description != "" {
+			multiline = true
+		}
+	}
+
+	a := make([]string, len(args))
+	for i, arg := range args {
+		v := arg.Name + ": " + arg.Type.String()
+		if arg.DefaultValue != nil {
+			v += " = " + formatValue(arg.DefaultValue)
+		}
+		v += formatDirectives(arg.Directives)
+		if multiline {
+			w := &SimpleWriter{}
+			writeDescription(w, prefix+indent, arg.Description)
+			w.s.WriteString(prefix + indent + v)
+			v = w.s.String()
+		}
+		a[i] = v
+	}
+
+	if multiline {
+		return "(" + lineBreak + strings.Join(a, lineBreak) + lineBreak + prefix + ")"
+	}
+	return "(" + strings.Join(a, ", ") + ")"
+}
+
+func formatDirectives(directives ast.DirectiveList) string {
+	var s strings.Builder
+	for _, d := range directives {
+		s.WriteString(" @" + d.Name)
+		if len(d.Arguments) == 0 {
+			continue
+		}
+		a := make([]string, len(d.Arguments))
+		for i, arg := range d.Arguments {
+			a[i] = arg.Name + ": " + formatValue(arg.Value)
+		}
+		s.WriteString("(" + strings.Join(a, ", ") + ")")
+	}
+	return s.String()
+}
+
+func formatValue(v *ast.Value) string {
+	switch v.Kind {
+	case ast.Variable:
+		return "$" + v.Raw
+	case ast.StringValue:
+		return strconv.Quote(v.Raw)
+	case ast.BlockValue:
+		return `"""` + strings.ReplaceAll(v.Raw, `"""`, `\"""`) + `"""`
+	case ast.ListValue:
+		a := make([]string, len(v.Children))
+		for i, child := range v.Children {
+			a[i] = formatValue(child.Value)
+		}
+		return "[" + strings.Join(a, ", ") + "]"
+	case ast.ObjectValue:
+		a := make([]string, len(v.Children))
+		for i, child := range v.Children {
+			a[i] = child.Name + ": " + formatValue(child.Value)
+		}
+		return "{" + strings.Join(a, ", ") + "}"
+	default:
+		return v.Raw
+	}
+}
+
+func writeDescription(w *SimpleWriter, prefix string, description string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, lineBreak) {
+		w.l(prefix + strconv.Quote(description))
+		return
+	}
+	w.l(prefix + `"""`)
+	for _, line := range strings.Split(description, lineBreak) {
+		if line == "" {
+			w.br()
+			continue
+		}
+		w.l(prefix + strings.ReplaceAll(line, `"""`, `\"""`))
+	}
+	w.l(prefix + `"""`)
+}
+
+// comments are the # comments of a source, which the parser leaves out. A comment after code belongs to
+// the token before it, a comment on a line of its own to the token after it.
+type comments struct {
+	tokens []lexer.Token
+	before map[int][]string
+	after  map[int][]string
+}
+
+func readComments(src *ast.Source) (*comments, *gqlerror.Error) {
+	c := &comments{before: map[int][]string{}, after: map[int][]string{}}
+	runes := []rune(src.Input)
+	l := lexer.New(src)
+	end := 0
+	for {
+		tok, err := l.ReadToken()
+		if err != nil {
+			return nil, err
+		}
+		i := len(c.tokens)
+		c.tokens = append(c.tokens, tok)
+
+		// the gap between two tokens holds white space, commas and comments only
+		for n, line := range strings.Split(string(runes[end:tok.Pos.Start]), "\n") {
+			hash := strings.IndexByte(line, '#')
+			if hash < 0 {
+				continue
+			}
+			comment := strings.TrimRight(line[hash:], " \t\r")
+			if n == 0 && i > 0 {
+				c.after[i-1] = append(c.after[i-1], comment)
+			} else {
+				c.before[i] = append(c.before[i], comment)
+			}
+		}
+		end = tok.Pos.End
+
+		if tok.Kind == lexer.EOF {
+			return c, nil
+		}
+	}
+}
+
+// index returns the token at the position
+func (c *comments) index(pos *ast.Position) int {
+	return sort.Search(len(c.tokens), func(i int) bool { return c.tokens[i].Pos.Start >= pos.Start })
+}
+
+func (c *comments) eof() int {
+	return len(c.tokens) - 1
+}
+
+// blockStart returns the first token of the top level definition at the position, the position is the
+// one of its name so the description and keywords before it are included
+func (c *comments) blockStart(pos *ast.Position) int {
+	i := c.index(pos)
+	for i > 0 {
+		prev := c.tokens[i-1]
+		if prev.Kind == lexer.String || prev.Kind == lexer.BlockString || prev.Kind == lexer.At ||
+			prev.Kind == lexer.Name && blockKeywords[prev.Value] {
+			i--
+			continue
+		}
+		break
+	}
+	return i
+}
+
+var blockKeywords = map[string]bool{
+	"schema":    true,
+	"scalar":    true,
+	"type":      true,
+	"interface": true,
+	"union":     true,
+	"enum":      true,
+	"input":     true,
+	"directive": true,
+	"extend":    true,
+}
+
+// writeLeading writes the comments on their own line before the tokens from to to
+func (c *comments) writeLeading(w *SimpleWriter, prefix string, from, to int) {
+	for i := from; i <= to; i++ {
+		for _, comment := range c.before[i] {
+			w.l(prefix + comment)
+		}
+	}
+}
+
+// trailing returns the comments after the code of the tokens from to to, these are put at the end of the
+// printed line
+func (c *comments) trailing(from, to int) string {
+	var a []string
+	for i := from; i <= to; i++ {
+		a = append(a, c.after[i]...)
+	}
+	if len(a) == 0 {
+		return ""
+	}
+	return " " + strings.Join(a, " ")
+}
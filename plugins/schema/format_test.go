@@ -0,0 +1,198 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "schema and directive definitions",
+			src: `schema { query: Query   mutation: Mutation }
+directive @auth(requires: Role = ADMIN, scopes: [String!]) on OBJECT | FIELD_DEFINITION
+`,
+			want: `schema {
+  query: Query
+  mutation: Mutation
+}
+
+directive @auth(requires: Role = ADMIN, scopes: [String!]) on OBJECT | FIELD_DEFINITION
+`,
+		},
+		{
+			name: "descriptions",
+			src: `"""
+A user of the app
+"""
+type User {
+  """
+  The display name
+  with two lines
+  """
+  name: String
+  "the email"   email: String
+}
+"""
+First line
+second line
+"""
+type Post { title: String }
+`,
+			want: `"A user of the app"
+type User {
+  """
+  The display name
+  with two lines
+  """
+  name: String
+  "the email"
+  email: String
+}
+
+"""
+First line
+second line
+"""
+type Post {
+  title: String
+}
+`,
+		},
+		{
+			name: "comments",
+			src: `# leading file comment
+type User {
+  id: ID!   # trailing id comment
+  # own line comment before name
+  name: String
+  # comment before the closing brace
+}
+# comment before a definition
+type Post {
+  post(
+    # which one
+    id: ID!
+  ): Post
+}
+# end of file comment
+`,
+			want: `# leading file comment
+type User {
+  id: ID! # trailing id comment
+  # own line comment before name
+  name: String
+  # comment before the closing brace
+}
+
+# comment before a definition
+type Post {
+  # which one
+  post(id: ID!): Post
+}
+
+# end of file comment
+`,
+		},
+		{
+			name: "directives with arguments",
+			src: `type User implements Node @key(fields: "id") @cache(maxAge: 60, scope: PRIVATE) {
+  name(format: String = "short"): String @auth(requires: USER, scopes: ["read", "write"])
+}
+`,
+			want: `type User implements Node @key(fields: "id") @cache(maxAge: 60, scope: PRIVATE) {
+  name(format: String = "short"): String @auth(requires: USER, scopes: ["read", "write"])
+}
+`,
+		},
+		{
+			name: "extend type",
+			src: `extend type Query {
+  users(first: Int = 10): [User!]!   posts: [Post!]!
+}
+`,
+			want: `extend type Query {
+  users(first: Int = 10): [User!]!
+  posts: [Post!]!
+}
+`,
+		},
+		{
+			name: "enums",
+			src: `enum Role {
+  ADMIN # the admin
+  "a user"
+  USER   GUEST
+}
+`,
+			want: `enum Role {
+  ADMIN # the admin
+  "a user"
+  USER
+  GUEST
+}
+`,
+		},
+		{
+			name: "input defaults",
+			src: `input UserFilter { name: String = "a"   ids: [ID!] = []   where: NameFilter = {equalTo: "a", in: ["b", "c"]}   limit: Int = 10 }
+`,
+			want: `input UserFilter {
+  name: String = "a"
+  ids: [ID!] = []
+  where: NameFilter = {equalTo: "a", in: ["b", "c"]}
+  limit: Int = 10
+}
+`,
+		},
+		{
+			name: "strings with comment characters",
+			src: `type User {
+  name: String @deprecated(reason: "use # instead") # real comment
+}
+`,
+			want: `type User {
+  name: String @deprecated(reason: "use # instead") # real comment
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatSchema("test.graphql", tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+
+			again, err := formatSchema("test.graphql", got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != got {
+				t.Errorf("formatting the formatted schema changed it to\n%s", again)
+			}
+		})
+	}
+}
+
+func TestFormatSchemaErrors(t *testing.T) {
+	for _, src := range []string{
+		"type User {",
+		"type User { name: }",
+		`type User { name: String @auth(requires: "open) }`,
+		"enum Role { ADMIN",
+	} {
+		if got, err := formatSchema("broken.graphql", src); err == nil {
+			t.Errorf("%q formatted to %q, want an error", src, got)
+		} else if !strings.Contains(err.Error(), "broken.graphql") {
+			t.Errorf("%q failed with %q, want the filename in the error", src, err)
+		}
+	}
+}
@@ -3,13 +3,16 @@ package schema
 import (
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/frankie-seb/sinatra/internal"
 	"github.com/iancoleman/strcase"
 	"github.com/rs/zerolog/log"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 const (
@@ -20,6 +23,10 @@ const (
 type SchemaArr struct {
 	Name string
 	Data string
+	// Fingerprint is a hash over the config and the models the schema of the group is rendered from
+	Fingerprint string
+	// Unchanged is set when the file of the group is up to date, the schema is not rendered then
+	Unchanged bool
 }
 
 type HooksConfig struct {
@@ -35,8 +42,9 @@ type SchemaGenerateConfig struct {
 }
 
 type SchemaModel struct {
-	Name   string
-	Fields []*SchemaField
+	Name        string
+	BoilerModel *internal.BoilerModel
+	Fields      []*SchemaField
 }
 
 type SchemaField struct {
@@ -100,32 +108,81 @@ const (
 	ParentTypeBatchCreate ParentType = "BatchCreate"
 )
 
-func SchemaWrite(cfg *internal.Config, hooks *HooksConfig) error {
+// SchemaWrite generates the schema and writes a file per group of models, files whose content did not
+// change since the last run according to the cache are left untouched. The returned report holds the
+// error of every group which failed.
+func SchemaWrite(cfg *internal.Config, hooks *HooksConfig, cache *internal.Cache) error {
 	// Generate schema based on config
-	schema := SchemaGet(
+	schemaFile := func(s SchemaArr) string {
+		return filepath.Join(cfg.Schema.DirName, strings.ToLower(s.Name)+"_gen.graphql")
+	}
+	schema := schemaGet(
 		cfg,
 		hooks,
+		func(s SchemaArr) bool { return cache.Unchanged(schemaFile(s), s.Fingerprint) },
 	)
 
-	ch := make(chan error)
+	if err := os.MkdirAll(cfg.Schema.DirName, os.ModePerm); err != nil {
+		return fmt.Errorf("could not create schema directory %v: %v", cfg.Schema.DirName, err)
+	}
+
+	ch := make(chan *internal.GenerateError)
 
 	for _, s := range schema {
 		go func(s SchemaArr) {
-			if err := writeContentToFile(s.Data, "schema/"+strings.ToLower(s.Name)+"_gen.graphql"); err != nil {
-				log.Err(err).Msg("Could not write schema to disk")
-				ch <- err
+			if s.Unchanged {
+				ch <- nil
+				return
+			}
+			filename := schemaFile(s)
+			content, err := formatSchema(filename, s.Data)
+			if err != nil {
+				// keep the unformatted schema so the reported position can be looked up
+				if err := writeContentToFile(s.Data, filename); err != nil {
+					log.Err(err).Msg("Could not write invalid schema to disk")
+				}
+				ch <- schemaError(filename, s.Name, s.Data, err)
+				return
+			}
+			if err := writeContentToFile(content, filename); err != nil {
+				ch <- &internal.GenerateError{File: filename, Model: s.Name, Message: err.Error()}
 				return
 			}
-			formatFile("schema/" + strings.ToLower(s.Name) + "_gen.graphql")
+			cache.Set(filename, s.Fingerprint)
 			ch <- nil
 		}(s)
 	}
 
-	if err := <-ch; err != nil {
-		return err
+	// wait for all files, so none is left half written when returning
+	report := &internal.Report{}
+	for range schema {
+		if err := <-ch; err != nil {
+			log.Err(err).Msg("Could not write schema to disk")
+			report.Add(err)
+		}
 	}
 
-	return nil
+	return report.Err()
+}
+
+var schemaFieldRe = regexp.MustCompile(`^\s*(\w+)\s*[:(]`)
+
+// schemaError names the position and the field of the generated schema which could not be parsed
+func schemaError(filename string, model string, data string, err error) *internal.GenerateError {
+	e := &internal.GenerateError{File: filename, Model: model, Message: err.Error()}
+	gqlErr, ok := err.(*gqlerror.Error)
+	if !ok || len(gqlErr.Locations) == 0 {
+		return e
+	}
+
+	e.Message = gqlErr.Message
+	e.Line, e.Column = gqlErr.Locations[0].Line, gqlErr.Locations[0].Column
+	if lines := strings.Split(data, lineBreak); e.Line > 0 && e.Line <= len(lines) {
+		if m := schemaFieldRe.FindStringSubmatch(lines[e.Line-1]); m != nil {
+			e.Field = m[1]
+		}
+	}
+	return e
 }
 
 func getDirectivesAsString(va []string) string {
@@ -154,22 +211,43 @@ func groupByModelName(list []*SchemaModel) [][]*SchemaModel {
 	return r
 }
 
-//nolint:gocognit,gocyclo
 func SchemaGet(
 	cfg *internal.Config,
 	hooks *HooksConfig,
+) []SchemaArr {
+	return schemaGet(cfg, hooks, nil)
+}
+
+// schemaGet renders the schema of every group, the groups for which unchanged reports that their file is
+// up to date are not rendered
+//
+//nolint:gocognit,gocyclo
+func schemaGet(
+	cfg *internal.Config,
+	hooks *HooksConfig,
+	unchanged func(s SchemaArr) bool,
 ) []SchemaArr {
 	d := []SchemaArr{}
 	g := &SimpleWriter{}
 	e := &SimpleWriter{}
 
 	// Parse models and their fields based on the sqlboiler model directory
-	boilerModels, boilerEnums := internal.GetBoilerModels(cfg.Model.DirName)
+	boilerModels, boilerEnums := internal.LoadBoilerModels(cfg)
 
-	models := executeHooksOnModels(boilerModelsToModels(boilerModels, cfg.Federation.ForeignIDs), hooks)
+	models := boilerModelsToModels(boilerModels, cfg.Federation.ForeignIDs)
+	applyTablesConfig(models, cfg.Tables, boilerEnums)
+	models = executeHooksOnModels(models, hooks)
 
 	grpMod := groupByModelName(models)
 
+	// every group is rendered from the config and its models, the hooks are code and only whether they
+	// are set is part of the fingerprint
+	base := internal.Fingerprint(internal.FingerprintJSON(cfg), hooksFingerprint(hooks))
+	keep := func(s *SchemaArr) bool {
+		s.Unchanged = unchanged != nil && unchanged(*s)
+		return s.Unchanged
+	}
+
 	// Directives GraphQL
 	fullDirectives := make([]string, len(cfg.Schema.Directives))
 	for i, defaultDirective := range cfg.Schema.Directives {
@@ -178,6 +256,13 @@ func SchemaGet(
 
 	joinedDirectives := strings.Join(fullDirectives, " ")
 
+	// the permanentlyDelete mutations are guarded by their own directives on top of the default ones
+	permanentDeleteDirectives := append([]string(nil), fullDirectives...)
+	for _, directive := range cfg.Schema.PermanentDeleteDirectives {
+		permanentDeleteDirectives = append(permanentDeleteDirectives, "@"+directive)
+	}
+	joinedPermanentDeleteDirectives := strings.Join(permanentDeleteDirectives, " ")
+
 	// Common File
 	g.l(`scalar Any`)
 	g.l(`scalar AnyFilter`)
@@ -198,6 +283,7 @@ func SchemaGet(
 	// Common Types
 	g.l("type Query {")
 	g.tl("node(id: ID!): Node")
+	g.tl("nodes(ids: [ID!]!): [Node]!")
 	g.l(`}`)
 
 	g.l(`interface Node {`)
@@ -223,17 +309,22 @@ func SchemaGet(
 	// Generate sorting helpers
 	g.l("enum SortDirection { ASC, DESC }")
 
+	// the common and enum schemas are cheap to render, only writing them is skipped when they are up to date
 	gVal := SchemaArr{
-		Name: "Common",
-		Data: g.s.String(),
+		Name:        "Common",
+		Data:        g.s.String(),
+		Fingerprint: base,
 	}
+	keep(&gVal)
 
 	d = append(d, gVal)
 
 	en := SchemaArr{
-		Name: "Enum",
-		Data: e.s.String(),
+		Name:        "Enum",
+		Data:        e.s.String(),
+		Fingerprint: internal.Fingerprint(base, internal.FingerprintJSON(boilerEnums)),
 	}
+	keep(&en)
 
 	for _, enum := range boilerEnums {
 
@@ -253,70 +344,179 @@ func SchemaGet(
 	d = append(d, en)
 
 	if len(grpMod) > 0 {
+		// the mutations are listed up front, also for the groups which are up to date, as the first group
+		// with mutations declares the Mutation type and the others extend it
+		mutations := make([]string, len(grpMod))
 		for i, grp := range grpMod {
-			w := &SimpleWriter{}
-			w.l("extend type Query {")
-			for _, model := range grp {
-				modelPluralName := internal.Plural(model.Name)
-
-				// single models
-				w.tl(strcase.ToLowerCamel(model.Name) + "(id: ID!): " + model.Name + "!" + joinedDirectives)
-
-				// lists
-				arguments := []string{
-					"first: Int!",
-					"after: String",
-					"ordering: [" + model.Name + "Ordering!]",
-					"filter: " + model.Name + "Filter",
-				}
-				w.tl(
-					strcase.ToLowerCamel(modelPluralName) + "(" + strings.Join(arguments, ", ") + "): " +
-						model.Name + "Connection!" + joinedDirectives)
-			}
-			w.l("}")
-
-			w.br()
-			if i == 0 {
-				w.l("type Mutation {")
-			} else {
-				w.l("extend type Mutation {")
-			}
+			m := &SimpleWriter{}
 			for _, model := range grp {
 				modelPluralName := internal.Plural(model.Name)
+				table := cfg.Tables.Get(model.BoilerModel.TableName)
 				// Generate mutation queries
 
 				// create single
 				// e.g createUser(input: UserInput!): UserPayload!
-				w.tl("create" + model.Name + "(input: " + model.Name + "CreateInput!): " +
-					model.Name + "Payload!" + joinedDirectives)
+				if !table.Skips(internal.OperationCreate) {
+					m.tl("create" + model.Name + "(input: " + model.Name + "CreateInput!): " +
+						model.Name + "Payload!" + joinedDirectives)
+				}
 
 				// create multiple
 				// e.g createUsers(input: [UsersInput!]!): UsersPayload!
-				w.tl("create" + modelPluralName + "(input: " + modelPluralName + "CreateInput!): " +
-					modelPluralName + "Payload!" + joinedDirectives)
+				if !table.Skips(internal.OperationBatchCreate) {
+					m.tl("create" + modelPluralName + "(input: " + modelPluralName + "CreateInput!): " +
+						modelPluralName + "Payload!" + joinedDirectives)
+				}
 
 				// update single
 				// e.g updateUser(id: ID!, input: UserInput!): UserPayload!
-				w.tl("update" + model.Name + "(id: ID!, input: " + model.Name + "UpdateInput!): " +
-					model.Name + "Payload!" + joinedDirectives)
+				if !table.Skips(internal.OperationUpdate) {
+					m.tl("update" + model.Name + "(id: ID!, input: " + model.Name + "UpdateInput!): " +
+						model.Name + "Payload!" + joinedDirectives)
+				}
 
 				// update multiple (batch update)
 				// e.g updateUsers(filter: UserFilter, input: UsersInput!): UsersPayload!
-				w.tl("update" + modelPluralName + "(filter: " + model.Name + "Filter, input: " +
-					model.Name + "UpdateInput!): " + modelPluralName + "UpdatePayload!" + joinedDirectives)
+				if !table.Skips(internal.OperationBatchUpdate) {
+					m.tl("update" + modelPluralName + "(filter: " + model.Name + "Filter, input: " +
+						model.Name + "UpdateInput!): " + modelPluralName + "UpdatePayload!" + joinedDirectives)
+				}
+
+				// update multiple with an input per row
+				// e.g updateUsersByID(input: [UserBatchUpdateItem!]!): UsersPayload!
+				if !table.Skips(internal.OperationBatchUpdateByID) {
+					m.tl("update" + modelPluralName + "ByID(input: [" + model.Name + "BatchUpdateItem!]!): " +
+						modelPluralName + "Payload!" + joinedDirectives)
+				}
 
 				// delete single
 				// e.g deleteUser(id: ID!): UserPayload!
-				w.tl("delete" + model.Name + "(id: ID!): " + model.Name + "DeletePayload!" + joinedDirectives)
+				if !table.Skips(internal.OperationDelete) {
+					m.tl("delete" + model.Name + "(id: ID!): " + model.Name + "DeletePayload!" + joinedDirectives)
+				}
 
 				// delete multiple
 				// e.g deleteUsers(filter: UserFilter, input: [UsersInput!]!): UsersPayload!
-				w.tl("delete" + modelPluralName + "(filter: " + model.Name + "Filter): " +
-					modelPluralName + "DeletePayload!" + joinedDirectives)
+				if !table.Skips(internal.OperationBatchDelete) {
+					m.tl("delete" + modelPluralName + "(filter: " + model.Name + "Filter): " +
+						modelPluralName + "DeletePayload!" + joinedDirectives)
+				}
+
+				// undo soft deletes
+				// e.g restoreUser(id: ID!): UserPayload!
+				// e.g restoreUsers(filter: UserFilter): UsersPayload!
+				if hasSoftDeletes(cfg, model) && !table.Skips(internal.OperationRestore) {
+					m.tl("restore" + model.Name + "(id: ID!): " + model.Name + "Payload!" + joinedDirectives)
+					m.tl("restore" + modelPluralName + "(filter: " + model.Name + "Filter): " +
+						modelPluralName + "Payload!" + joinedDirectives)
+				}
+
+				// delete a soft deleted row for good, only with the permanent delete directives
+				// e.g permanentlyDeleteUser(id: ID!): UserDeletePayload! @isAdmin
+				if hasSoftDeletes(cfg, model) && len(cfg.Schema.PermanentDeleteDirectives) > 0 &&
+					!table.Skips(internal.OperationPermanentDelete) {
+					m.tl("permanentlyDelete" + model.Name + "(id: ID!): " + model.Name + "DeletePayload!" +
+						joinedPermanentDeleteDirectives)
+				}
+
+				// upsert on one of the unique keys
+				// e.g upsertUser(input: UserCreateInput!, onConflict: UserConflictTarget!): UserPayload!
+				if hasUpsert(cfg, model) {
+					m.tl("upsert" + model.Name + "(input: " + model.Name + "CreateInput!, onConflict: " +
+						model.Name + "ConflictTarget!): " + model.Name + "Payload!" + joinedDirectives)
+				}
 
+				// link and unlink the models of many-to-many relations
+				// e.g addPostTags(id: ID!, ids: [ID!]!): PostPayload!
+				if !table.Skips(internal.OperationManyToMany) {
+					for _, relation := range manyToManyRelations(models, model) {
+						for _, action := range []string{"add", "remove", "set"} {
+							m.tl(action + model.Name + relation.BoilerField.Name + "(id: ID!, ids: [ID!]!): " +
+								model.Name + "Payload!" + joinedDirectives)
+						}
+					}
+				}
+			}
+			mutations[i] = m.s.String()
+		}
+
+		mutationDeclared := false
+		for i, grp := range grpMod {
+			declaresMutation := !mutationDeclared && mutations[i] != ""
+			mutationDeclared = mutationDeclared || mutations[i] != ""
+
+			mod := SchemaArr{
+				Name: internal.GetFirstWord(grp[0].Name),
+				Fingerprint: internal.Fingerprint(
+					base,
+					strconv.FormatBool(declaresMutation),
+					groupFingerprint(models, grp),
+				),
+			}
+			if keep(&mod) {
+				d = append(d, mod)
+				continue
+			}
+
+			w := &SimpleWriter{}
+			q := &SimpleWriter{}
+			for _, model := range grp {
+				modelPluralName := internal.Plural(model.Name)
+				table := cfg.Tables.Get(model.BoilerModel.TableName)
+
+				// single models
+				if !table.Skips(internal.OperationSingle) {
+					q.tl(strcase.ToLowerCamel(model.Name) + "(id: ID!): " + model.Name + "!" + joinedDirectives)
+				}
+
+				// lists
+				if !table.Skips(internal.OperationList) {
+					// relay pagination, forward with first and after or backward with last and before
+					arguments := []string{
+						"first: Int",
+						"after: String",
+						"last: Int",
+						"before: String",
+						"ordering: [" + model.Name + "Ordering!]",
+						"filter: " + model.Name + "Filter",
+					}
+					// soft deleted rows are left out unless they are asked for
+					if hasSoftDeletes(cfg, model) {
+						arguments = append(arguments, "includeDeleted: Boolean", "onlyDeleted: Boolean")
+					}
+					q.tl(
+						strcase.ToLowerCamel(modelPluralName) + "(" + strings.Join(arguments, ", ") + "): " +
+							model.Name + "Connection!" + joinedDirectives)
+				}
+
+				// aggregates
+				if !table.Skips(internal.OperationAggregate) {
+					arguments := []string{"filter: " + model.Name + "Filter"}
+					if len(internal.GetAggregateColumns(cfg, model.BoilerModel).Groups) > 0 {
+						arguments = append(arguments, "groupBy: ["+model.Name+"GroupBy!]")
+					}
+					q.tl(
+						strcase.ToLowerCamel(model.Name) + "Aggregate(" + strings.Join(arguments, ", ") + "): [" +
+							model.Name + "Aggregate!]!" + joinedDirectives)
+				}
+			}
+			if q.s.Len() > 0 {
+				w.l("extend type Query {")
+				w.s.WriteString(q.s.String())
+				w.l("}")
+				w.br()
+			}
+
+			if mutations[i] != "" {
+				if declaresMutation {
+					w.l("type Mutation {")
+				} else {
+					w.l("extend type Mutation {")
+				}
+				w.s.WriteString(mutations[i])
+				w.l("}")
+				w.br()
 			}
-			w.l("}")
-			w.br()
 
 			for _, model := range grp {
 				//	enum UserSort { FIRST_NAME, LAST_NAME }
@@ -340,6 +540,17 @@ func SchemaGet(
 
 				w.br()
 
+				//	enum UserConflictTarget { EMAIL }
+				if hasUpsert(cfg, model) {
+					w.l("enum " + model.Name + "ConflictTarget {")
+					for _, key := range model.BoilerModel.UniqueKeys {
+						w.tl(key.ConflictTargetValue())
+					}
+					w.l("}")
+
+					w.br()
+				}
+
 				// Create basic structs e.g.
 				// type User {
 				// 	firstName: String!
@@ -437,7 +648,7 @@ func SchemaGet(
 					if field.BoilerField.IsRelation {
 						// Support filtering in relationships (at least schema wise)
 						relationName := getRelationName(field)
-						w.tl(relationName + ": " + field.BoilerField.Relationship.Name + "Where" + directives)
+						w.tl(relationName + ": " + field.BoilerField.Relationship.GraphName + "Where" + directives)
 					} else {
 						w.tl(field.Name + ": " + field.Type + "Filter" + directives)
 					}
@@ -456,32 +667,23 @@ func SchemaGet(
 				// 	firstName: String!
 				// 	lastName: String
 				//	organizationId: ID!
+				//	posts: [PostCreateWithoutUserInput!]
 				// }
-				w.l("input " + model.Name + "CreateInput {")
-
-				for _, field := range enhanceFields(hooks, model, filteredFields, ParentTypeCreate) {
-					if field.SkipInput || field.SkipCreate {
-						continue
-					}
-					// id is not required in create and will be specified in update resolver
-					if field.Name == "id" || field.Name == "createdAt" || field.Name == "updatedAt" || field.Name == "deletedAt" {
-						continue
-					}
-					// not possible yet in input
-					// TODO: make this possible for one-to-one structs?
-					// only for foreign keys inside model itself
-					if field.BoilerField.IsRelation && field.BoilerField.IsArray ||
-						field.BoilerField.IsRelation && !strings.HasSuffix(field.BoilerField.Name, "ID") {
-						continue
-					}
-					directives := getDirectivesAsString(field.InputDirectives)
-					fullType := getFinalFullType(field, ParentTypeCreate)
-					w.tl(field.Name + ": " + fullType + directives)
-				}
-				w.l("}")
+				writeCreateInput(w, cfg, hooks, models, model, model.Name+"CreateInput", nil)
 
 				w.br()
 
+				// the inputs of the nested creates of the models with a to-many relation to this one
+				// input PostCreateWithoutUserInput {
+				// 	title: String!
+				// }
+				for _, foreignKey := range nestedCreateForeignKeys(cfg, models, model) {
+					writeCreateInput(w, cfg, hooks, models, model,
+						internal.NestedCreateInputName(model.BoilerModel, foreignKey), foreignKey)
+
+					w.br()
+				}
+
 				// input UserUpdateInput {
 				// 	firstName: String!
 				// 	lastName: String
@@ -518,12 +720,16 @@ func SchemaGet(
 
 				w.br()
 
-				// if batchUpdate {
-				// 	w.l("input " + modelPluralName + "UpdateInput {")
-				// 	w.tl(strcase.ToLowerCamel(modelPluralName) + ": [" + model.Name + "UpdateInput!]!")
-				// 	w.l("}")
-				// 	w.br()
+				// input UserBatchUpdateItem {
+				// 	id: ID!
+				// 	input: UserUpdateInput!
 				// }
+				w.l("input " + model.Name + "BatchUpdateItem {")
+				w.tl("id: ID!")
+				w.tl("input: " + model.Name + "UpdateInput!")
+				w.l("}")
+
+				w.br()
 
 				// type UserPayload {
 				// 	user: User!
@@ -571,12 +777,12 @@ func SchemaGet(
 
 				w.br()
 
+				if !cfg.Tables.Get(model.BoilerModel.TableName).Skips(internal.OperationAggregate) {
+					writeAggregateTypes(w, model, internal.GetAggregateColumns(cfg, model.BoilerModel))
+				}
+
 			}
-			// Append to array
-			mod := SchemaArr{
-				Name: internal.GetFirstWord(grp[0].Name),
-				Data: w.s.String(),
-			}
+			mod.Data = w.s.String()
 			d = append(d, mod)
 		}
 	}
@@ -584,6 +790,244 @@ func SchemaGet(
 	return d
 }
 
+// groupFingerprint returns a hash over the models of the group and the models related to them, as the
+// tables config and the hooks left them
+func groupFingerprint(models []*SchemaModel, grp []*SchemaModel) string {
+	all := make([]*internal.BoilerModel, len(models))
+	for i, model := range models {
+		all[i] = model.BoilerModel
+	}
+	own := make([]*internal.BoilerModel, len(grp))
+	for i, model := range grp {
+		own[i] = model.BoilerModel
+	}
+	names := internal.RelatedModelNames(own, all)
+
+	var parts []string
+	for _, model := range models {
+		if names[model.BoilerModel.Name] {
+			parts = append(parts, modelFingerprint(model))
+		}
+	}
+	return internal.Fingerprint(parts...)
+}
+
+// schemaFieldInput is a field without the related models of its boiler field, these refer back to it
+type schemaFieldInput struct {
+	*SchemaField
+	BoilerField string
+}
+
+func modelFingerprint(model *SchemaModel) string {
+	fields := make([]schemaFieldInput, len(model.Fields))
+	for i, f := range model.Fields {
+		fields[i] = schemaFieldInput{SchemaField: f, BoilerField: internal.BoilerFieldFingerprint(f.BoilerField)}
+	}
+	return internal.Fingerprint(model.Name, internal.BoilerModelFingerprint(model.BoilerModel), internal.FingerprintJSON(fields))
+}
+
+func hooksFingerprint(hooks *HooksConfig) string {
+	return fmt.Sprint(
+		hooks.HookShouldAddModel != nil,
+		hooks.HookShouldAddField != nil,
+		hooks.HookChangeField != nil,
+		hooks.HookChangeFields != nil,
+		hooks.HookChangeModel != nil,
+	)
+}
+
+// hasUpsert reports whether the upsert mutation is generated for the model, it needs a unique key
+// to use as conflict target
+func hasUpsert(cfg *internal.Config, model *SchemaModel) bool {
+	return len(model.BoilerModel.UniqueKeys) > 0 &&
+		!cfg.Tables.Get(model.BoilerModel.TableName).Skips(internal.OperationUpsert)
+}
+
+// hasSoftDeletes reports whether the rows of the model are soft deleted, sqlboiler only soft deletes
+// tables with a deleted_at column
+func hasSoftDeletes(cfg *internal.Config, model *SchemaModel) bool {
+	return cfg.Database.AddSoftDeletes && model.BoilerModel.CanSoftDelete()
+}
+
+// writeAggregateTypes writes the result of the {model}Aggregate query e.g.
+//
+//	type UserAggregate {
+//		count: Int!
+//		sum: UserAggregateNumbers
+//		avg: UserAggregateNumbers
+//		min: UserAggregateValues
+//		max: UserAggregateValues
+//		group: UserAggregateGroup
+//	}
+func writeAggregateTypes(w *SimpleWriter, model *SchemaModel, columns *internal.AggregateColumns) {
+	w.l("type " + model.Name + "Aggregate {")
+	w.tl("count: Int!")
+	if len(columns.Numbers) > 0 {
+		w.tl("sum: " + model.Name + "AggregateNumbers")
+		w.tl("avg: " + model.Name + "AggregateNumbers")
+	}
+	if columns.HasValues() {
+		w.tl("min: " + model.Name + "AggregateValues")
+		w.tl("max: " + model.Name + "AggregateValues")
+	}
+	if len(columns.Groups) > 0 {
+		w.tl("group: " + model.Name + "AggregateGroup")
+	}
+	w.l("}")
+	w.br()
+
+	if len(columns.Numbers) > 0 {
+		w.l("type " + model.Name + "AggregateNumbers {")
+		for _, c := range columns.Numbers {
+			w.tl(c.Name + ": Float")
+		}
+		w.l("}")
+		w.br()
+	}
+
+	if columns.HasValues() {
+		w.l("type " + model.Name + "AggregateValues {")
+		for _, c := range columns.Numbers {
+			w.tl(c.Name + ": Float")
+		}
+		for _, c := range columns.Times {
+			w.tl(c.Name + ": Time")
+		}
+		w.l("}")
+		w.br()
+	}
+
+	if len(columns.Groups) > 0 {
+		// the values of the groupBy columns, foreign keys are the global id of the relationship
+		w.l("type " + model.Name + "AggregateGroup {")
+		for _, c := range columns.Groups {
+			if c.BoilerField.IsEnum {
+				w.tl(c.Name + ": " + c.BoilerField.Enum.Name)
+			} else {
+				w.tl(c.Name + ": ID")
+			}
+		}
+		w.l("}")
+		w.br()
+
+		w.l("enum " + model.Name + "GroupBy {")
+		for _, c := range columns.Groups {
+			w.tl(c.GroupByValue())
+		}
+		w.l("}")
+		w.br()
+	}
+}
+
+// writeCreateInput writes the create input of the model, the nested create inputs leave out the foreign
+// key which is set from the created model
+func writeCreateInput(
+	w *SimpleWriter,
+	cfg *internal.Config,
+	hooks *HooksConfig,
+	models []*SchemaModel,
+	model *SchemaModel,
+	name string,
+	withoutForeignKey *internal.BoilerField,
+) {
+	w.l("input " + name + " {")
+
+	filteredFields := fieldsWithout(model.Fields, cfg.Schema.SkipInputFields)
+	for _, field := range enhanceFields(hooks, model, filteredFields, ParentTypeCreate) {
+		if field.SkipInput || field.SkipCreate {
+			continue
+		}
+		// id is not required in create and will be specified in update resolver
+		if field.Name == "id" || field.Name == "createdAt" || field.Name == "updatedAt" || field.Name == "deletedAt" {
+			continue
+		}
+		if withoutForeignKey != nil && field.BoilerField.Name == withoutForeignKey.Name {
+			continue
+		}
+		directives := getDirectivesAsString(field.InputDirectives)
+		// to-many relations are created together with the model
+		if field.BoilerField.IsRelation && field.BoilerField.IsArray {
+			if related, foreignKey := nestedCreateRelation(cfg, models, model, field.BoilerField); related != nil {
+				nestedName := internal.NestedCreateInputName(related.BoilerModel, foreignKey)
+				w.tl(field.Name + ": [" + nestedName + "!]" + directives)
+			}
+			continue
+		}
+		// not possible yet in input
+		// TODO: make this possible for one-to-one structs?
+		// only for foreign keys inside model itself
+		if field.BoilerField.IsRelation && !strings.HasSuffix(field.BoilerField.Name, "ID") {
+			continue
+		}
+		w.tl(field.Name + ": " + getFinalFullType(field, ParentTypeCreate) + directives)
+	}
+	w.l("}")
+}
+
+// manyToManyRelations returns the relations of the model which go through a join table, relations to
+// models which are not in the schema are left out
+func manyToManyRelations(models []*SchemaModel, model *SchemaModel) []*SchemaField {
+	var a []*SchemaField
+	for _, field := range model.Fields {
+		if !internal.IsManyToMany(field.BoilerField) {
+			continue
+		}
+		for _, related := range models {
+			if related.BoilerModel.Name == field.BoilerField.Relationship.Name {
+				a = append(a, field)
+				break
+			}
+		}
+	}
+	return a
+}
+
+// nestedCreateRelation returns the related model and its foreign key when the to-many relation can be
+// created together with the model, relations through a join table and related models which can't be
+// created are left out
+func nestedCreateRelation(
+	cfg *internal.Config,
+	models []*SchemaModel,
+	model *SchemaModel,
+	relation *internal.BoilerField,
+) (*SchemaModel, *internal.BoilerField) {
+	foreignKey := internal.HasManyForeignKey(model.BoilerModel, relation)
+	if foreignKey == nil {
+		return nil, nil
+	}
+	for _, related := range models {
+		if related.BoilerModel.Name != relation.Relationship.Name {
+			continue
+		}
+		if cfg.Tables.Get(related.BoilerModel.TableName).Skips(internal.OperationCreate) {
+			return nil, nil
+		}
+		return related, foreignKey
+	}
+	return nil, nil
+}
+
+// nestedCreateForeignKeys returns the foreign keys of the model for which a nested create input is
+// written, one for every to-many relation of another model which is created together with it
+func nestedCreateForeignKeys(cfg *internal.Config, models []*SchemaModel, model *SchemaModel) []*internal.BoilerField {
+	var a []*internal.BoilerField
+	seen := map[string]bool{}
+	for _, parent := range models {
+		for _, field := range parent.Fields {
+			if !field.BoilerField.IsRelation || !field.BoilerField.IsArray {
+				continue
+			}
+			related, foreignKey := nestedCreateRelation(cfg, models, parent, field.BoilerField)
+			if related != model || seen[foreignKey.Name] {
+				continue
+			}
+			seen[foreignKey.Name] = true
+			a = append(a, foreignKey)
+		}
+	}
+	return a
+}
+
 func enhanceFields(hooks *HooksConfig, model *SchemaModel, fields []*SchemaField, parentType ParentType) []*SchemaField {
 	if hooks.HookChangeFields != nil {
 		return hooks.HookChangeFields(model, fields, parentType)
@@ -595,7 +1039,8 @@ func fieldAsEnumStrings(fields []*SchemaField) []string {
 	var enums []string
 	for _, field := range fields {
 		if field.BoilerField != nil && !field.SkipSort && (!field.BoilerField.IsRelation && !field.BoilerField.IsForeignKey) {
-			enums = append(enums, strcase.ToScreamingSnake(field.Name))
+			// the sort values are mapped on the database columns, so renamed fields keep the column name
+			enums = append(enums, strcase.ToScreamingSnake(internal.ToGraphQLName(field.BoilerField.Name)))
 		}
 	}
 	return enums
@@ -620,13 +1065,65 @@ func boilerModelsToModels(boilerModels []*internal.BoilerModel, foreignIDs *[]in
 	a := make([]*SchemaModel, len(boilerModels))
 	for i, boilerModel := range boilerModels {
 		a[i] = &SchemaModel{
-			Name:   boilerModel.Name,
-			Fields: boilerFieldsToFields(boilerModel.Fields, foreignIDs),
+			Name:        boilerModel.GraphName,
+			BoilerModel: boilerModel,
+			Fields:      boilerFieldsToFields(boilerModel.Fields, foreignIDs),
 		}
 	}
 	return a
 }
 
+// filterTypes are the types which have a filter input in the common schema
+var filterTypes = []string{"ID", "String", "Int", "Float", "Boolean", "Time", "Any"}
+
+// applyTablesConfig applies the column overrides of the tables config, it runs before the hooks so
+// these still see the final fields
+func applyTablesConfig(models []*SchemaModel, tables internal.TablesConfig, enums []*internal.BoilerEnum) {
+	for _, m := range models {
+		table := tables.Get(m.BoilerModel.TableName)
+		if len(table.Columns) == 0 {
+			continue
+		}
+		var af []*SchemaField
+		for _, f := range m.Fields {
+			column := table.Column(f.BoilerField.Name)
+			if column.Hidden {
+				continue
+			}
+			if column.ReadOnly {
+				f.SkipCreate = true
+				f.SkipUpdate = true
+				f.SkipBatchCreate = true
+				f.SkipBatchUpdate = true
+			}
+			// relations keep the type and name of the model they point to
+			if !f.BoilerField.IsRelation {
+				if column.Type != "" {
+					f.Type = column.Type
+					f.SetInputTypeForAllInputs(column.Type)
+					if !internal.SliceContains(filterTypes, column.Type) && !isEnum(enums, column.Type) {
+						f.SkipWhere = true
+					}
+				}
+				if column.Name != "" {
+					f.Name = column.Name
+				}
+			}
+			af = append(af, f)
+		}
+		m.Fields = af
+	}
+}
+
+func isEnum(enums []*internal.BoilerEnum, name string) bool {
+	for _, e := range enums {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // executeHooksOnModels removes models and fields which the user hooked in into + it can change values
 func executeHooksOnModels(models []*SchemaModel, hooks *HooksConfig) []*SchemaModel {
 	var a []*SchemaModel
@@ -675,7 +1172,7 @@ func getFinalFullTypeWithRelation(schemaField *SchemaField, parentType ParentTyp
 	boilerField := schemaField.BoilerField
 	alwaysOptional := getAlwaysOptional(parentType)
 	if boilerField.Relationship != nil {
-		relationType := boilerField.Relationship.Name
+		relationType := boilerField.Relationship.GraphName
 		if alwaysOptional {
 			return getFullType(
 				relationType,
@@ -730,27 +1227,7 @@ func getFieldType(schemaField *SchemaField, parentType ParentType) string {
 
 func boilerFieldToField(boilerField *internal.BoilerField, foreignIDs *[]internal.ForeignIDColumn) *SchemaField {
 	t := toGraphQLType(boilerField, foreignIDs)
-	return NewSchemaField(toGraphQLName(boilerField.Name), t, boilerField)
-}
-
-func toGraphQLName(fieldName string) string {
-	graphqlName := fieldName
-
-	// Golang ID to Id the right way
-	// Primary key
-	if graphqlName == "ID" {
-		graphqlName = "id"
-	}
-
-	if graphqlName == "URL" {
-		graphqlName = "url"
-	}
-
-	// e.g. OrganizationID, TODO: more robust solution?
-	graphqlName = strings.Replace(graphqlName, "ID", "Id", -1)
-	graphqlName = strings.Replace(graphqlName, "URL", "Url", -1)
-
-	return strcase.ToLowerCamel(graphqlName)
+	return NewSchemaField(internal.ToGraphQLName(boilerField.Name), t, boilerField)
 }
 
 func toGraphQLType(boilerField *internal.BoilerField, foreignIDs *[]internal.ForeignIDColumn) string {
@@ -811,20 +1288,6 @@ func fieldsWithout(fields []*SchemaField, skipFieldNames []string) []*SchemaFiel
 	return filteredFields
 }
 
-func formatFile(filename string) error {
-	name := "prettier"
-	args := []string{filename, "--write"}
-
-	cmd := exec.Command(name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("executing command: '%v %v' failed with: %v", name, strings.Join(args, " "), err)
-	}
-	return nil
-}
-
 func writeContentToFile(content string, filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
@@ -0,0 +1,1318 @@
+package sqlboiler
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/frankie-seb/sinatra/internal"
+)
+
+// The DDL parser understands the subset of postgres statements which changes the shape of tables:
+// create/alter/drop table, create/alter/drop type (enums), create/drop index and comment on column.
+// Everything else (functions, triggers, grants, inserts, ...) is skipped. It is written for the
+// output of pg_dump --schema-only and for hand written migrations, not as a full SQL parser.
+
+type tokenKind uint8
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	quoted bool
+	start  int
+	end    int
+}
+
+// lexStatements splits the sql into statements of tokens, comments are dropped
+func lexStatements(src string) ([][]token, error) {
+	var statements [][]token
+	var current []token
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+
+		case strings.HasPrefix(src[i:], "--"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(src[i:], "/*"):
+			start := i
+			depth := 0
+			for i < len(src) {
+				if strings.HasPrefix(src[i:], "/*") {
+					depth++
+					i += 2
+				} else if strings.HasPrefix(src[i:], "*/") {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			if depth > 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", start)
+			}
+
+		case c == ';':
+			if len(current) > 0 {
+				statements = append(statements, current)
+			}
+			current = nil
+			i++
+
+		case c == '\'' || ((c == 'E' || c == 'e') && i+1 < len(src) && src[i+1] == '\''):
+			start := i
+			escapes := c != '\''
+			if escapes {
+				i++
+			}
+			text, end, err := lexQuoted(src, i, '\'', escapes)
+			if err != nil {
+				return nil, err
+			}
+			current = append(current, token{kind: tokenString, text: text, start: start, end: end})
+			i = end
+
+		case c == '"':
+			text, end, err := lexQuoted(src, i, '"', false)
+			if err != nil {
+				return nil, err
+			}
+			current = append(current, token{kind: tokenIdent, text: text, quoted: true, start: i, end: end})
+			i = end
+
+		case c == '$' && i+1 < len(src) && !isDigit(src[i+1]):
+			// dollar quoted string, e.g. function bodies: $$ ... $$ or $body$ ... $body$
+			tagEnd := strings.IndexByte(src[i+1:], '$')
+			if tagEnd < 0 {
+				return nil, fmt.Errorf("unterminated dollar quote at offset %d", i)
+			}
+			tag := src[i : i+tagEnd+2]
+			bodyEnd := strings.Index(src[i+len(tag):], tag)
+			if bodyEnd < 0 {
+				return nil, fmt.Errorf("unterminated dollar quote %s at offset %d", tag, i)
+			}
+			end := i + len(tag) + bodyEnd + len(tag)
+			current = append(current, token{kind: tokenString, text: src[i+len(tag) : end-len(tag)], start: i, end: end})
+			i = end
+
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			current = append(current, token{kind: tokenIdent, text: strings.ToLower(src[start:i]), start: start, end: i})
+
+		case isDigit(c):
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.' || src[i] == 'e' || src[i] == 'E') {
+				i++
+			}
+			current = append(current, token{kind: tokenNumber, text: src[start:i], start: start, end: i})
+
+		case strings.HasPrefix(src[i:], "::"):
+			current = append(current, token{kind: tokenSymbol, text: "::", start: i, end: i + 2})
+			i += 2
+
+		default:
+			current = append(current, token{kind: tokenSymbol, text: string(c), start: i, end: i + 1})
+			i++
+		}
+	}
+
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+	return statements, nil
+}
+
+func lexQuoted(src string, i int, quote byte, backslashEscapes bool) (string, int, error) {
+	var b strings.Builder
+	for j := i + 1; j < len(src); j++ {
+		switch {
+		case backslashEscapes && src[j] == '\\' && j+1 < len(src):
+			j++
+			b.WriteByte(src[j])
+		case src[j] == quote && j+1 < len(src) && src[j+1] == quote:
+			j++
+			b.WriteByte(quote)
+		case src[j] == quote:
+			return b.String(), j + 1, nil
+		default:
+			b.WriteByte(src[j])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated %c quote at offset %d", quote, i)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '$'
+}
+
+// ddlSchema is the state of the database after applying all statements
+type ddlSchema struct {
+	defaultSchema string
+	tables        map[string]*ddlTable
+	enums         map[string][]string
+	indexes       map[string]*ddlIndex
+}
+
+type ddlTable struct {
+	schema  string
+	name    string
+	columns []*ddlColumn
+	pkey    *ddlConstraint
+	fkeys   []*ddlForeignKey
+	uniques []*ddlConstraint
+}
+
+type ddlColumn struct {
+	name       string
+	dataType   string
+	udtName    string
+	typeSchema string
+	arrType    *string
+	nullable   bool
+	def        string
+	generated  bool
+	comment    string
+}
+
+type ddlConstraint struct {
+	name    string
+	columns []string
+}
+
+type ddlForeignKey struct {
+	name           string
+	columns        []string
+	foreignSchema  string
+	foreignTable   string
+	foreignColumns []string
+}
+
+type ddlIndex struct {
+	schema  string
+	table   string
+	unique  bool
+	columns []string
+}
+
+func newDDLSchema(defaultSchema string) *ddlSchema {
+	return &ddlSchema{
+		defaultSchema: defaultSchema,
+		tables:        map[string]*ddlTable{},
+		enums:         map[string][]string{},
+		indexes:       map[string]*ddlIndex{},
+	}
+}
+
+func qualifiedName(schema, name string) string {
+	return schema + "." + name
+}
+
+func (s *ddlSchema) table(schema, name string) *ddlTable {
+	return s.tables[qualifiedName(schema, name)]
+}
+
+func (t *ddlTable) column(name string) *ddlColumn {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (t *ddlTable) dropColumn(name string) {
+	for i, c := range t.columns {
+		if c.name == name {
+			t.columns = append(t.columns[:i], t.columns[i+1:]...)
+			break
+		}
+	}
+
+	// postgres drops the constraints using the column together with it
+	if t.pkey != nil && internal.SliceContains(t.pkey.columns, name) {
+		t.pkey = nil
+	}
+	var fkeys []*ddlForeignKey
+	for _, fk := range t.fkeys {
+		if !internal.SliceContains(fk.columns, name) {
+			fkeys = append(fkeys, fk)
+		}
+	}
+	t.fkeys = fkeys
+	var uniques []*ddlConstraint
+	for _, u := range t.uniques {
+		if !internal.SliceContains(u.columns, name) {
+			uniques = append(uniques, u)
+		}
+	}
+	t.uniques = uniques
+}
+
+func (t *ddlTable) dropConstraint(name string) {
+	if t.pkey != nil && t.pkey.name == name {
+		t.pkey = nil
+	}
+	for i, fk := range t.fkeys {
+		if fk.name == name {
+			t.fkeys = append(t.fkeys[:i], t.fkeys[i+1:]...)
+			break
+		}
+	}
+	for i, u := range t.uniques {
+		if u.name == name {
+			t.uniques = append(t.uniques[:i], t.uniques[i+1:]...)
+			break
+		}
+	}
+}
+
+func renameInList(list []string, from, to string) {
+	for i, s := range list {
+		if s == from {
+			list[i] = to
+		}
+	}
+}
+
+// apply parses the sql and applies its statements to the schema
+func (s *ddlSchema) apply(src string) error {
+	statements, err := lexStatements(src)
+	if err != nil {
+		return err
+	}
+	for _, tokens := range statements {
+		p := &ddlParser{schema: s, src: src, tokens: tokens}
+		if err := p.statement(); err != nil {
+			return fmt.Errorf("%v in statement: %s", err, statementPreview(src, tokens))
+		}
+	}
+	return nil
+}
+
+func statementPreview(src string, tokens []token) string {
+	text := strings.Join(strings.Fields(src[tokens[0].start:tokens[len(tokens)-1].end]), " ")
+	if len(text) > 80 {
+		return text[:77] + "..."
+	}
+	return text
+}
+
+type ddlParser struct {
+	schema *ddlSchema
+	src    string
+	tokens []token
+	pos    int
+}
+
+func (p *ddlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *ddlParser) peek() token {
+	if p.done() {
+		return token{kind: tokenSymbol}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// is reports whether the next tokens are the given keywords
+func (p *ddlParser) is(keywords ...string) bool {
+	for i, k := range keywords {
+		if p.pos+i >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.pos+i]
+		if t.quoted || t.kind == tokenString || t.text != k {
+			return false
+		}
+	}
+	return true
+}
+
+// accept consumes the keywords when they are next
+func (p *ddlParser) accept(keywords ...string) bool {
+	if !p.is(keywords...) {
+		return false
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *ddlParser) expect(keywords ...string) error {
+	if !p.accept(keywords...) {
+		return fmt.Errorf("expected %s but got %q", strings.Join(keywords, " "), p.peek().text)
+	}
+	return nil
+}
+
+func (p *ddlParser) ident() (string, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return "", fmt.Errorf("expected identifier but got %q", t.text)
+	}
+	return t.text, nil
+}
+
+// qualifiedName reads a possibly schema qualified name, unqualified names use the default schema
+func (p *ddlParser) qualifiedName() (string, string, error) {
+	name, err := p.ident()
+	if err != nil {
+		return "", "", err
+	}
+	if p.accept(".") {
+		table, err := p.ident()
+		if err != nil {
+			return "", "", err
+		}
+		return name, table, nil
+	}
+	return p.schema.defaultSchema, name, nil
+}
+
+func (p *ddlParser) identList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		p.skipUntil(",", ")")
+		if p.accept(")") {
+			return names, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// skipGroup skips the next token, or a whole parenthesized group
+func (p *ddlParser) skipGroup() {
+	if t := p.peek(); t.kind != tokenSymbol || t.text != "(" {
+		p.next()
+		return
+	}
+	depth := 0
+	for !p.done() {
+		switch p.next().text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// skipUntil skips tokens until one of the symbols is next on the current nesting level
+func (p *ddlParser) skipUntil(symbols ...string) {
+	for !p.done() {
+		t := p.peek()
+		if t.kind == tokenSymbol && internal.SliceContains(symbols, t.text) {
+			return
+		}
+		p.skipGroup()
+	}
+}
+
+func (p *ddlParser) statement() error {
+	switch {
+	case p.accept("create"):
+		p.accept("or", "replace")
+		p.accept("global")
+		p.accept("local")
+		p.accept("unlogged")
+		if p.accept("temporary") || p.accept("temp") {
+			return nil
+		}
+		switch {
+		case p.accept("table"):
+			return p.createTable()
+		case p.accept("type"):
+			return p.createType()
+		case p.accept("unique", "index"):
+			return p.createIndex(true)
+		case p.accept("index"):
+			return p.createIndex(false)
+		}
+	case p.accept("alter", "table"):
+		return p.alterTable()
+	case p.accept("alter", "type"):
+		return p.alterType()
+	case p.accept("drop", "table"):
+		return p.dropTable()
+	case p.accept("drop", "type"):
+		return p.dropType()
+	case p.accept("drop", "index"):
+		return p.dropIndex()
+	case p.accept("comment", "on", "column"):
+		return p.commentOnColumn()
+	}
+	return nil
+}
+
+func (p *ddlParser) createTable() error {
+	ifNotExists := p.accept("if", "not", "exists")
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if ifNotExists && p.schema.table(schema, name) != nil {
+		return nil
+	}
+	// CREATE TABLE ... AS SELECT and partitions have no column list we could read
+	if !p.accept("(") {
+		return nil
+	}
+
+	t := &ddlTable{schema: schema, name: name}
+	p.schema.tables[qualifiedName(schema, name)] = t
+
+	if p.accept(")") {
+		return nil
+	}
+	for {
+		if err := p.tableElement(t); err != nil {
+			return err
+		}
+		if p.accept(")") {
+			return nil
+		}
+		if err := p.expect(","); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *ddlParser) tableElement(t *ddlTable) error {
+	if p.is("constraint") || p.is("primary", "key") || p.is("unique") ||
+		p.is("foreign", "key") || p.is("check") || p.is("exclude") {
+		return p.tableConstraint(t)
+	}
+	if p.accept("like") {
+		p.skipUntil(",", ")")
+		return nil
+	}
+	return p.columnDefinition(t)
+}
+
+func (p *ddlParser) tableConstraint(t *ddlTable) error {
+	var name string
+	if p.accept("constraint") {
+		var err error
+		if name, err = p.ident(); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case p.accept("primary", "key"):
+		columns, err := p.identList()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = t.name + "_pkey"
+		}
+		t.pkey = &ddlConstraint{name: name, columns: columns}
+		for _, c := range columns {
+			if col := t.column(c); col != nil {
+				col.nullable = false
+			}
+		}
+
+	case p.accept("unique"):
+		p.skipNullsDistinct()
+		columns, err := p.identList()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = t.name + "_" + strings.Join(columns, "_") + "_key"
+		}
+		t.uniques = append(t.uniques, &ddlConstraint{name: name, columns: columns})
+
+	case p.accept("foreign", "key"):
+		columns, err := p.identList()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("references"); err != nil {
+			return err
+		}
+		fk, err := p.references(t, name, columns)
+		if err != nil {
+			return err
+		}
+		t.fkeys = append(t.fkeys, fk)
+	}
+
+	p.skipUntil(",", ")")
+	return nil
+}
+
+func (p *ddlParser) skipNullsDistinct() {
+	if p.accept("nulls", "not", "distinct") {
+		return
+	}
+	p.accept("nulls", "distinct")
+}
+
+func (p *ddlParser) references(t *ddlTable, name string, columns []string) (*ddlForeignKey, error) {
+	schema, table, err := p.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	fk := &ddlForeignKey{
+		name:          name,
+		columns:       columns,
+		foreignSchema: schema,
+		foreignTable:  table,
+	}
+	if p.peek().text == "(" {
+		if fk.foreignColumns, err = p.identList(); err != nil {
+			return nil, err
+		}
+	}
+	// skip the match type and referential actions, ON DELETE SET NULL is no column constraint
+	for {
+		switch {
+		case p.accept("match"):
+			p.next()
+		case p.accept("on", "delete") || p.accept("on", "update"):
+			if p.accept("set", "null") || p.accept("set", "default") {
+				if p.peek().text == "(" {
+					p.skipGroup()
+				}
+			} else if !p.accept("no", "action") {
+				p.next()
+			}
+		default:
+			if fk.name == "" {
+				fk.name = t.name + "_" + strings.Join(columns, "_") + "_fkey"
+			}
+			return fk, nil
+		}
+	}
+}
+
+// columnConstraintKeywords end a default expression
+var columnConstraintKeywords = []string{
+	"constraint", "not", "null", "primary", "unique", "references", "check",
+	"collate", "generated", "default", "deferrable", "initially",
+}
+
+func (p *ddlParser) columnDefinition(t *ddlTable) error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	col, serial, err := p.columnType()
+	if err != nil {
+		return err
+	}
+	col.name = name
+	col.nullable = true
+	if serial {
+		col.nullable = false
+		col.def = fmt.Sprintf("nextval('%s_%s_seq'::regclass)", t.name, name)
+	}
+
+	if err := p.columnConstraints(t, col); err != nil {
+		return err
+	}
+	t.columns = append(t.columns, col)
+	return nil
+}
+
+func (p *ddlParser) columnConstraints(t *ddlTable, col *ddlColumn) error {
+	for !p.done() && p.peek().text != "," && p.peek().text != ")" {
+		var name string
+		if p.accept("constraint") {
+			var err error
+			if name, err = p.ident(); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case p.accept("not", "null"):
+			col.nullable = false
+		case p.accept("null"):
+			col.nullable = true
+		case p.accept("default"):
+			col.def = p.expression()
+		case p.accept("primary", "key"):
+			col.nullable = false
+			if name == "" {
+				name = t.name + "_pkey"
+			}
+			t.pkey = &ddlConstraint{name: name, columns: []string{col.name}}
+		case p.accept("unique"):
+			p.skipNullsDistinct()
+			if name == "" {
+				name = t.name + "_" + col.name + "_key"
+			}
+			t.uniques = append(t.uniques, &ddlConstraint{name: name, columns: []string{col.name}})
+		case p.accept("references"):
+			fk, err := p.references(t, name, []string{col.name})
+			if err != nil {
+				return err
+			}
+			t.fkeys = append(t.fkeys, fk)
+		case p.accept("generated"):
+			p.generated(col)
+		default:
+			p.skipGroup()
+		}
+	}
+	return nil
+}
+
+// generated handles GENERATED ... AS IDENTITY and GENERATED ALWAYS AS (expr) STORED
+func (p *ddlParser) generated(col *ddlColumn) {
+	p.accept("always")
+	p.accept("by", "default")
+	p.accept("as")
+	if p.accept("identity") {
+		col.def = "IDENTITY"
+		col.nullable = false
+		if p.peek().text == "(" {
+			p.skipGroup()
+		}
+		return
+	}
+	col.generated = true
+	p.skipGroup()
+	p.accept("stored")
+}
+
+// expression reads a default expression and returns its source text
+func (p *ddlParser) expression() string {
+	if p.done() {
+		return ""
+	}
+	start := p.peek().start
+	end := p.peek().end
+	for first := true; !p.done(); first = false {
+		t := p.peek()
+		if t.kind == tokenSymbol && (t.text == "," || t.text == ")") {
+			break
+		}
+		if !first && t.kind == tokenIdent && !t.quoted && internal.SliceContains(columnConstraintKeywords, t.text) &&
+			p.tokens[p.pos-1].text != "::" {
+			break
+		}
+		p.skipGroup()
+		end = p.tokens[p.pos-1].end
+	}
+	return p.src[start:end]
+}
+
+// pgTypes maps the type names and aliases to the data type and udt name postgres reports in the
+// information schema
+var pgTypes = map[string][2]string{
+	"smallint":                    {"smallint", "int2"},
+	"int2":                        {"smallint", "int2"},
+	"integer":                     {"integer", "int4"},
+	"int":                         {"integer", "int4"},
+	"int4":                        {"integer", "int4"},
+	"bigint":                      {"bigint", "int8"},
+	"int8":                        {"bigint", "int8"},
+	"smallserial":                 {"smallint", "int2"},
+	"serial2":                     {"smallint", "int2"},
+	"serial":                      {"integer", "int4"},
+	"serial4":                     {"integer", "int4"},
+	"bigserial":                   {"bigint", "int8"},
+	"serial8":                     {"bigint", "int8"},
+	"real":                        {"real", "float4"},
+	"float4":                      {"real", "float4"},
+	"double precision":            {"double precision", "float8"},
+	"float8":                      {"double precision", "float8"},
+	"float":                       {"double precision", "float8"},
+	"numeric":                     {"numeric", "numeric"},
+	"decimal":                     {"numeric", "numeric"},
+	"money":                       {"money", "money"},
+	"boolean":                     {"boolean", "bool"},
+	"bool":                        {"boolean", "bool"},
+	"text":                        {"text", "text"},
+	"character varying":           {"character varying", "varchar"},
+	"varchar":                     {"character varying", "varchar"},
+	"character":                   {"character", "bpchar"},
+	"char":                        {"character", "bpchar"},
+	"bpchar":                      {"character", "bpchar"},
+	`"char"`:                      {`"char"`, "char"},
+	"bytea":                       {"bytea", "bytea"},
+	"date":                        {"date", "date"},
+	"timestamp":                   {"timestamp without time zone", "timestamp"},
+	"timestamp without time zone": {"timestamp without time zone", "timestamp"},
+	"timestamp with time zone":    {"timestamp with time zone", "timestamptz"},
+	"timestamptz":                 {"timestamp with time zone", "timestamptz"},
+	"time":                        {"time without time zone", "time"},
+	"time without time zone":      {"time without time zone", "time"},
+	"time with time zone":         {"time with time zone", "timetz"},
+	"timetz":                      {"time with time zone", "timetz"},
+	"interval":                    {"interval", "interval"},
+	"uuid":                        {"uuid", "uuid"},
+	"json":                        {"json", "json"},
+	"jsonb":                       {"jsonb", "jsonb"},
+	"xml":                         {"xml", "xml"},
+	"inet":                        {"inet", "inet"},
+	"cidr":                        {"cidr", "cidr"},
+	"macaddr":                     {"macaddr", "macaddr"},
+	"bit":                         {"bit", "bit"},
+	"bit varying":                 {"bit varying", "varbit"},
+	"varbit":                      {"bit varying", "varbit"},
+	"oid":                         {"oid", "oid"},
+	"point":                       {"point", "point"},
+	"line":                        {"line", "line"},
+	"lseg":                        {"lseg", "lseg"},
+	"box":                         {"box", "box"},
+	"path":                        {"path", "path"},
+	"polygon":                     {"polygon", "polygon"},
+	"circle":                      {"circle", "circle"},
+}
+
+var serialTypes = []string{"smallserial", "serial2", "serial", "serial4", "bigserial", "serial8"}
+
+// columnType reads a column type, serial reports whether it's one of the serial pseudo types
+func (p *ddlParser) columnType() (*ddlColumn, bool, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return nil, false, fmt.Errorf("expected column type but got %q", t.text)
+	}
+	name := t.text
+	typeSchema := ""
+	if t.quoted && name == "char" {
+		name = `"char"`
+	}
+	if p.accept(".") {
+		typeSchema = name
+		var err error
+		if name, err = p.ident(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	switch name {
+	case "double":
+		p.accept("precision")
+		name = "double precision"
+	case "character", "char", "bit":
+		if p.accept("varying") {
+			name += " varying"
+			if name == "char varying" {
+				name = "character varying"
+			}
+		}
+	case "national":
+		p.accept("character")
+		p.accept("char")
+		name = "character"
+		if p.accept("varying") {
+			name = "character varying"
+		}
+	}
+
+	// modifiers like varchar(255) or numeric(10, 2)
+	if p.peek().text == "(" {
+		p.skipGroup()
+	}
+
+	switch name {
+	case "timestamp", "time":
+		if p.accept("with", "time", "zone") {
+			name += " with time zone"
+		} else if p.accept("without", "time", "zone") {
+			name += " without time zone"
+		}
+	case "interval":
+		for p.accept("year") || p.accept("month") || p.accept("day") || p.accept("hour") ||
+			p.accept("minute") || p.accept("second") || p.accept("to") {
+		}
+		if p.peek().text == "(" {
+			p.skipGroup()
+		}
+	}
+
+	col := &ddlColumn{typeSchema: typeSchema}
+	if known, ok := pgTypes[name]; ok && (typeSchema == "" || typeSchema == "pg_catalog") {
+		col.dataType, col.udtName = known[0], known[1]
+	} else {
+		col.dataType, col.udtName = "USER-DEFINED", name
+		if typeSchema == "" {
+			col.typeSchema = p.schema.defaultSchema
+		}
+	}
+	serial := typeSchema == "" && internal.SliceContains(serialTypes, name)
+
+	// arrays: int[], int[3][3] or int ARRAY[3]
+	isArray := false
+	for p.peek().text == "[" || p.is("array") {
+		isArray = true
+		p.accept("array")
+		if p.peek().text == "[" {
+			p.skipUntil("]")
+			p.next()
+		}
+	}
+	if isArray {
+		arrType := col.dataType
+		col.arrType = &arrType
+		col.dataType = "ARRAY"
+		col.udtName = "_" + col.udtName
+	}
+
+	return col, serial, nil
+}
+
+func (p *ddlParser) alterTable() error {
+	p.accept("if", "exists")
+	p.accept("only")
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	t := p.schema.table(schema, name)
+	if t == nil {
+		return nil
+	}
+
+	if p.accept("rename", "to") {
+		newName, err := p.ident()
+		if err != nil {
+			return err
+		}
+		p.renameTable(t, newName)
+		return nil
+	}
+
+	for !p.done() {
+		if err := p.alterTableAction(t); err != nil {
+			return err
+		}
+		p.skipUntil(",")
+		if !p.accept(",") {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *ddlParser) alterTableAction(t *ddlTable) error {
+	switch {
+	case p.is("add", "constraint") || p.is("add", "primary") || p.is("add", "unique") ||
+		p.is("add", "foreign") || p.is("add", "check") || p.is("add", "exclude"):
+		p.next()
+		return p.tableConstraint(t)
+
+	case p.accept("add"):
+		p.accept("column")
+		p.accept("if", "not", "exists")
+		if t.column(p.peek().text) != nil {
+			return nil
+		}
+		return p.columnDefinition(t)
+
+	case p.accept("drop", "constraint"):
+		p.accept("if", "exists")
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		t.dropConstraint(name)
+
+	case p.accept("drop"):
+		p.accept("column")
+		p.accept("if", "exists")
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		t.dropColumn(name)
+
+	case p.accept("rename", "constraint"):
+		from, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("to"); err != nil {
+			return err
+		}
+		to, err := p.ident()
+		if err != nil {
+			return err
+		}
+		p.renameConstraint(t, from, to)
+
+	case p.accept("rename"):
+		p.accept("column")
+		from, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("to"); err != nil {
+			return err
+		}
+		to, err := p.ident()
+		if err != nil {
+			return err
+		}
+		p.renameColumn(t, from, to)
+
+	case p.accept("alter"):
+		p.accept("column")
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		col := t.column(name)
+		if col == nil {
+			return nil
+		}
+		return p.alterColumn(col)
+	}
+	return nil
+}
+
+func (p *ddlParser) alterColumn(col *ddlColumn) error {
+	switch {
+	case p.accept("set", "not", "null"):
+		col.nullable = false
+	case p.accept("drop", "not", "null"):
+		col.nullable = true
+	case p.accept("set", "default"):
+		col.def = p.expression()
+	case p.accept("drop", "default"):
+		col.def = ""
+	case p.accept("drop", "identity"):
+		col.def = ""
+	case p.accept("add", "generated"):
+		p.generated(col)
+	case p.accept("set", "data", "type") || p.accept("type"):
+		changed, _, err := p.columnType()
+		if err != nil {
+			return err
+		}
+		col.dataType = changed.dataType
+		col.udtName = changed.udtName
+		col.typeSchema = changed.typeSchema
+		col.arrType = changed.arrType
+	}
+	return nil
+}
+
+func (p *ddlParser) renameTable(t *ddlTable, name string) {
+	delete(p.schema.tables, qualifiedName(t.schema, t.name))
+	for _, other := range p.schema.tables {
+		for _, fk := range other.fkeys {
+			if fk.foreignSchema == t.schema && fk.foreignTable == t.name {
+				fk.foreignTable = name
+			}
+		}
+	}
+	for _, idx := range p.schema.indexes {
+		if idx.schema == t.schema && idx.table == t.name {
+			idx.table = name
+		}
+	}
+	t.name = name
+	p.schema.tables[qualifiedName(t.schema, t.name)] = t
+}
+
+func (p *ddlParser) renameColumn(t *ddlTable, from, to string) {
+	if col := t.column(from); col != nil {
+		col.name = to
+	}
+	if t.pkey != nil {
+		renameInList(t.pkey.columns, from, to)
+	}
+	for _, u := range t.uniques {
+		renameInList(u.columns, from, to)
+	}
+	for _, fk := range t.fkeys {
+		renameInList(fk.columns, from, to)
+	}
+	for _, other := range p.schema.tables {
+		for _, fk := range other.fkeys {
+			if fk.foreignSchema == t.schema && fk.foreignTable == t.name {
+				renameInList(fk.foreignColumns, from, to)
+			}
+		}
+	}
+	for _, idx := range p.schema.indexes {
+		if idx.schema == t.schema && idx.table == t.name {
+			renameInList(idx.columns, from, to)
+		}
+	}
+}
+
+func (p *ddlParser) renameConstraint(t *ddlTable, from, to string) {
+	if t.pkey != nil && t.pkey.name == from {
+		t.pkey.name = to
+	}
+	for _, u := range t.uniques {
+		if u.name == from {
+			u.name = to
+		}
+	}
+	for _, fk := range t.fkeys {
+		if fk.name == from {
+			fk.name = to
+		}
+	}
+}
+
+func (p *ddlParser) dropTable() error {
+	p.accept("if", "exists")
+	for {
+		schema, name, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		delete(p.schema.tables, qualifiedName(schema, name))
+		for key, idx := range p.schema.indexes {
+			if idx.schema == schema && idx.table == name {
+				delete(p.schema.indexes, key)
+			}
+		}
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+func (p *ddlParser) createType() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.accept("as", "enum") {
+		return nil
+	}
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	var labels []string
+	for !p.done() && !p.accept(")") {
+		t := p.next()
+		if t.kind == tokenString {
+			labels = append(labels, t.text)
+		}
+	}
+	p.schema.enums[qualifiedName(schema, name)] = labels
+	return nil
+}
+
+func (p *ddlParser) alterType() error {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	key := qualifiedName(schema, name)
+	labels, ok := p.schema.enums[key]
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case p.accept("add", "value"):
+		p.accept("if", "not", "exists")
+		label := p.next().text
+		if internal.SliceContains(labels, label) {
+			return nil
+		}
+		at := len(labels)
+		if p.accept("before") || p.accept("after") {
+			after := p.tokens[p.pos-1].text == "after"
+			other := p.next().text
+			for i, l := range labels {
+				if l == other {
+					at = i
+					if after {
+						at++
+					}
+				}
+			}
+		}
+		labels = append(labels[:at], append([]string{label}, labels[at:]...)...)
+		p.schema.enums[key] = labels
+
+	case p.accept("rename", "value"):
+		from := p.next().text
+		if err := p.expect("to"); err != nil {
+			return err
+		}
+		renameInList(labels, from, p.next().text)
+
+	case p.accept("rename", "to"):
+		to, err := p.ident()
+		if err != nil {
+			return err
+		}
+		delete(p.schema.enums, key)
+		p.schema.enums[qualifiedName(schema, to)] = labels
+		for _, t := range p.schema.tables {
+			for _, c := range t.columns {
+				if c.typeSchema == schema && strings.TrimPrefix(c.udtName, "_") == name {
+					c.udtName = strings.Replace(c.udtName, name, to, 1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *ddlParser) dropType() error {
+	p.accept("if", "exists")
+	for {
+		schema, name, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		delete(p.schema.enums, qualifiedName(schema, name))
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+func (p *ddlParser) createIndex(unique bool) error {
+	p.accept("concurrently")
+	p.accept("if", "not", "exists")
+	var name string
+	if !p.is("on") {
+		var err error
+		if name, err = p.ident(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("on"); err != nil {
+		return err
+	}
+	p.accept("only")
+	schema, table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if p.accept("using") {
+		p.next()
+	}
+
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	var columns []string
+	for {
+		column := p.next()
+		// expression indexes can't be mapped to columns
+		if column.kind != tokenIdent || p.peek().text == "(" {
+			return nil
+		}
+		columns = append(columns, column.text)
+		p.skipUntil(",", ")")
+		if p.accept(")") {
+			break
+		}
+		if err := p.expect(","); err != nil {
+			return err
+		}
+	}
+	if name == "" {
+		name = table + "_" + strings.Join(columns, "_") + "_idx"
+	}
+	p.schema.indexes[qualifiedName(schema, name)] = &ddlIndex{
+		schema:  schema,
+		table:   table,
+		unique:  unique,
+		columns: columns,
+	}
+	return nil
+}
+
+func (p *ddlParser) dropIndex() error {
+	p.accept("concurrently")
+	p.accept("if", "exists")
+	for {
+		schema, name, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		delete(p.schema.indexes, qualifiedName(schema, name))
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+func (p *ddlParser) commentOnColumn() error {
+	var parts []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		parts = append(parts, name)
+		if !p.accept(".") {
+			break
+		}
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+	if err := p.expect("is"); err != nil {
+		return err
+	}
+	comment := p.next()
+
+	schema := p.schema.defaultSchema
+	if len(parts) > 2 {
+		schema = parts[len(parts)-3]
+	}
+	t := p.schema.table(schema, parts[len(parts)-2])
+	if t == nil {
+		return nil
+	}
+	if col := t.column(parts[len(parts)-1]); col != nil && comment.kind == tokenString {
+		col.comment = comment.text
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+package sqlboiler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDDL(t *testing.T) {
+	tests := []struct {
+		name  string
+		ddl   string
+		table string
+		want  []string
+	}{
+		{
+			name: "create table",
+			ddl: `CREATE TABLE users (
+				id serial PRIMARY KEY,
+				email varchar(255) NOT NULL,
+				balance numeric(10, 2) DEFAULT 0.00,
+				tags text[],
+				created_at timestamp with time zone NOT NULL DEFAULT now(),
+				seen_at TIMESTAMP,
+				score double precision,
+				lower_email text GENERATED ALWAYS AS (lower(email)) STORED
+			);`,
+			table: "users",
+			want: []string{
+				"column id integer not null default nextval('users_id_seq'::regclass) unique",
+				"column email character varying not null",
+				"column balance numeric null default 0.00",
+				"column tags ARRAY(text) null",
+				"column created_at timestamp with time zone not null default now()",
+				"column seen_at timestamp without time zone null",
+				"column score double precision null",
+				"pkey users_pkey (id)",
+			},
+		},
+		{
+			name: "identity columns",
+			ddl: `CREATE TABLE events (
+				id bigint GENERATED ALWAYS AS IDENTITY (START WITH 10) PRIMARY KEY,
+				seq int GENERATED BY DEFAULT AS IDENTITY
+			);`,
+			table: "events",
+			want: []string{
+				"column id bigint not null default IDENTITY unique",
+				"column seq integer not null default IDENTITY",
+				"pkey events_pkey (id)",
+			},
+		},
+		{
+			name: "constraints",
+			ddl: `CREATE TABLE users (id bigserial PRIMARY KEY);
+			CREATE TABLE teams (org_id int, slug text, PRIMARY KEY (org_id, slug));
+			CREATE TABLE members (
+				id int NOT NULL,
+				user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+				org_id int,
+				team_slug text,
+				email text CONSTRAINT members_email_unique UNIQUE,
+				role text CHECK (role IN ('admin', 'member')),
+				CONSTRAINT members_id PRIMARY KEY (id),
+				UNIQUE (org_id, user_id),
+				FOREIGN KEY (org_id, team_slug) REFERENCES teams (org_id, slug) ON UPDATE CASCADE,
+				CHECK (org_id > 0)
+			);`,
+			table: "members",
+			want: []string{
+				"column id integer not null unique",
+				"column user_id bigint not null",
+				"column org_id integer null",
+				"column team_slug text null",
+				"column email text null unique",
+				"column role text null",
+				"pkey members_id (id)",
+				"fkey members_user_id_fkey user_id -> users.id",
+				"fkey members_org_id_team_slug_fkey org_id -> teams.org_id",
+				"fkey members_org_id_team_slug_fkey team_slug -> teams.slug",
+				"unique (email)",
+				"unique (org_id, user_id)",
+			},
+		},
+		{
+			name: "alter table",
+			ddl: `CREATE TABLE users (id int PRIMARY KEY);
+			CREATE TABLE post (id int, user_id int, title varchar(100), body text, draft boolean);
+			ALTER TABLE post ADD CONSTRAINT post_pk PRIMARY KEY (id);
+			ALTER TABLE post ADD COLUMN published_at timestamptz, DROP COLUMN draft;
+			ALTER TABLE post RENAME COLUMN body TO content;
+			ALTER TABLE post ALTER COLUMN title TYPE text, ALTER COLUMN title SET NOT NULL;
+			ALTER TABLE post ALTER COLUMN content SET DEFAULT '';
+			ALTER TABLE ONLY post ADD CONSTRAINT post_user_fk FOREIGN KEY (user_id) REFERENCES users (id);
+			ALTER TABLE post ADD CONSTRAINT post_title_key UNIQUE (title);
+			ALTER TABLE post DROP CONSTRAINT post_title_key;
+			ALTER TABLE post RENAME CONSTRAINT post_pk TO posts_pkey;
+			ALTER TABLE post RENAME TO posts;
+			ALTER TABLE IF EXISTS missing ADD COLUMN ignored int;`,
+			table: "posts",
+			want: []string{
+				"column id integer not null unique",
+				"column user_id integer null",
+				"column title text not null",
+				"column content text null default ''",
+				"column published_at timestamp with time zone null",
+				"pkey posts_pkey (id)",
+				"fkey post_user_fk user_id -> users.id",
+			},
+		},
+		{
+			name: "drop and rename referenced columns",
+			ddl: `CREATE TABLE users (id int PRIMARY KEY, email text UNIQUE);
+			CREATE TABLE posts (id int PRIMARY KEY, author_id int REFERENCES users (id), editor_id int REFERENCES users (id));
+			ALTER TABLE posts RENAME author_id TO user_id;
+			ALTER TABLE posts DROP COLUMN editor_id;`,
+			table: "posts",
+			want: []string{
+				"column id integer not null unique",
+				"column user_id integer null",
+				"pkey posts_pkey (id)",
+				"fkey posts_author_id_fkey user_id -> users.id",
+			},
+		},
+		{
+			name: "indexes",
+			ddl: `CREATE TABLE users (id int PRIMARY KEY, email text, org_id int, name text, login text);
+			CREATE UNIQUE INDEX users_email_idx ON users (email);
+			CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS users_org_name ON ONLY public.users USING btree (org_id, name) WHERE name IS NOT NULL;
+			CREATE INDEX users_name_idx ON users (name);
+			CREATE UNIQUE INDEX users_lower_login ON users (lower(login));
+			CREATE UNIQUE INDEX users_login ON users (login);
+			DROP INDEX users_login;`,
+			table: "users",
+			want: []string{
+				"column id integer not null unique",
+				"column email text null unique",
+				"column org_id integer null",
+				"column name text null",
+				"column login text null",
+				"pkey users_pkey (id)",
+				"unique (email)",
+				"unique (org_id, name)",
+			},
+		},
+		{
+			name: "quoted identifiers",
+			ddl: `CREATE TABLE "Users" ("ID" int PRIMARY KEY, "order" int, "Display Name" text, Nick text);
+			CREATE TABLE posts (id int, "userID" int REFERENCES "Users" ("ID"));`,
+			table: "Users",
+			want: []string{
+				"column ID integer not null unique",
+				"column order integer null",
+				"column Display Name text null",
+				"column nick text null",
+				"pkey Users_pkey (ID)",
+			},
+		},
+		{
+			name: "quoted foreign keys",
+			ddl: `CREATE TABLE "Users" ("ID" int PRIMARY KEY);
+			CREATE TABLE POSTS (ID int, "userID" int REFERENCES "Users" ("ID"));`,
+			table: "posts",
+			want: []string{
+				"column id integer null",
+				"column userID integer null",
+				"fkey posts_userID_fkey userID -> Users.ID",
+			},
+		},
+		{
+			name: "comments and strings",
+			ddl: `-- CREATE TABLE commented (id int);
+			/* CREATE TABLE block (id int); /* nested; */ still a comment; */
+			CREATE TABLE notes (
+				id int, -- the id; not a statement end
+				body text DEFAULT 'it''s -- not a comment; really',
+				raw text DEFAULT E'a\'; b'
+			);
+			CREATE FUNCTION touch() RETURNS trigger AS $body$
+				BEGIN
+					CREATE TABLE fake (id int);
+					RETURN NEW;
+				END;
+			$body$ LANGUAGE plpgsql;
+			COMMENT ON COLUMN notes.body IS 'the note''s text';
+			COMMENT ON COLUMN public.notes.id IS 'identifier';`,
+			table: "notes",
+			want: []string{
+				"column id integer null comment 'identifier'",
+				"column body text null default 'it''s -- not a comment; really' comment 'the note's text'",
+				"column raw text null default E'a\\'; b'",
+			},
+		},
+		{
+			name: "enums",
+			ddl: `CREATE TYPE mood AS ENUM ('sad', 'happy');
+			ALTER TYPE mood ADD VALUE 'ok' BEFORE 'happy';
+			ALTER TYPE mood ADD VALUE IF NOT EXISTS 'great' AFTER 'happy';
+			ALTER TYPE mood RENAME VALUE 'sad' TO 'blue';
+			CREATE TYPE status AS ENUM ('on', 'off');
+			ALTER TYPE status RENAME TO state;
+			CREATE TABLE people (id int, mood mood NOT NULL, moods mood[], state state);`,
+			table: "people",
+			want: []string{
+				"column id integer null",
+				"column mood enum.mood('blue','ok','happy','great') not null",
+				"column moods ARRAY(USER-DEFINED) null",
+				"column state enum.state('on','off') null",
+			},
+		},
+		{
+			name: "temporary and dropped tables",
+			ddl: `CREATE TEMP TABLE scratch (id int);
+			CREATE TABLE users (id int);
+			CREATE TABLE IF NOT EXISTS users (id int, ignored int);
+			CREATE TABLE old (id int);
+			DROP TABLE IF EXISTS old, missing CASCADE;`,
+			table: "users",
+			want: []string{
+				"column id integer null",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ddl := newDDLSchema("public")
+			if err := ddl.apply(tt.ddl); err != nil {
+				t.Fatal(err)
+			}
+			got := describeTable(t, &offlineDriver{ddl: ddl}, "public", tt.table)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got\n\t%s\nwant\n\t%s", strings.Join(got, "\n\t"), strings.Join(tt.want, "\n\t"))
+			}
+		})
+	}
+}
+
+func TestDDLTableNames(t *testing.T) {
+	ddl := newDDLSchema("public")
+	err := ddl.apply(`CREATE TABLE users (id int);
+		CREATE TABLE audit.events (id int);
+		CREATE TEMPORARY TABLE scratch (id int);
+		CREATE TABLE "Posts" (id int);
+		CREATE TABLE comments (id int);
+		DROP TABLE comments;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &offlineDriver{ddl: ddl}
+
+	for _, tt := range []struct {
+		schema string
+		want   []string
+	}{
+		{"public", []string{"Posts", "users"}},
+		{"audit", []string{"events"}},
+	} {
+		got, err := d.TableNames(tt.schema, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tables of %s are %q, want %q", tt.schema, got, tt.want)
+		}
+	}
+}
+
+func TestDDLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ddl  string
+		want string
+	}{
+		{"unterminated string", `CREATE TABLE t (name text DEFAULT 'oops);`, "unterminated"},
+		{"unterminated comment", `CREATE TABLE t (id int); /* never closed`, "unterminated"},
+		{"missing column type", `CREATE TABLE t (id);`, "CREATE TABLE t (id)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newDDLSchema("public").apply(tt.ddl)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDDL(t *testing.T) {
+	dir := t.TempDir()
+	migrations := map[string]string{
+		// versions are numbers, 10 runs after 2
+		"1_users.sql":        `CREATE TABLE users (id serial PRIMARY KEY);`,
+		"2_email.sql":        `ALTER TABLE users ADD COLUMN email text;`,
+		"10_rename.sql":      `ALTER TABLE users RENAME COLUMN email TO mail;`,
+		"10_rename.down.sql": `ALTER TABLE users RENAME COLUMN mail TO email;`,
+		"0011_posts.sql": `-- +migrate Up
+CREATE TABLE posts (id serial PRIMARY KEY, user_id int REFERENCES users);
+-- +migrate Down
+DROP TABLE posts;`,
+		"20210101120000_tags.sql": `-- +goose Up
+ALTER TABLE posts ADD COLUMN tags text[];
+-- +goose Down
+ALTER TABLE posts DROP COLUMN tags;`,
+		"20210102120000_titles.sql": `-- migrate:up
+ALTER TABLE posts ADD COLUMN title text NOT NULL DEFAULT '';
+
+-- migrate:down
+ALTER TABLE posts DROP COLUMN title;`,
+		"README.md": `DROP TABLE users;`,
+	}
+	for name, content := range migrations {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ddl, err := loadDDL(dir, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &offlineDriver{ddl: ddl}
+	for _, tt := range []struct {
+		table string
+		want  []string
+	}{
+		{"users", []string{
+			"column id integer not null default nextval('users_id_seq'::regclass) unique",
+			"column mail text null",
+			"pkey users_pkey (id)",
+		}},
+		{"posts", []string{
+			"column id integer not null default nextval('posts_id_seq'::regclass) unique",
+			"column user_id integer null",
+			"column tags ARRAY(text) null",
+			"column title text not null default ''",
+			"pkey posts_pkey (id)",
+			"fkey posts_user_id_fkey user_id -> users.id",
+		}},
+	} {
+		got := describeTable(t, d, "public", tt.table)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s is\n\t%s\nwant\n\t%s", tt.table, strings.Join(got, "\n\t"), strings.Join(tt.want, "\n\t"))
+		}
+	}
+
+	// a single file is applied as is
+	single := filepath.Join(dir, "2_email.sql")
+	if _, err := loadDDL(single, "public"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadDDL(filepath.Join(dir, "missing.sql"), "public"); err == nil {
+		t.Error("loading a missing file succeeded")
+	}
+}
+
+func TestSortMigrations(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{
+			files: []string{"m/10_c.sql", "m/2_b.sql", "m/1_a.sql"},
+			want:  []string{"m/1_a.sql", "m/2_b.sql", "m/10_c.sql"},
+		},
+		{
+			files: []string{"002_b.sql", "10_c.sql", "001_a.sql"},
+			want:  []string{"001_a.sql", "002_b.sql", "10_c.sql"},
+		},
+		{
+			files: []string{"20210102_b.sql", "20210101_a.sql", "99999999999999999999_big.sql", "3_small.sql"},
+			want:  []string{"3_small.sql", "20210101_a.sql", "20210102_b.sql", "99999999999999999999_big.sql"},
+		},
+		{
+			files: []string{"b.sql", "1_b.sql", "a.sql", "1_a.sql"},
+			want:  []string{"a.sql", "b.sql", "1_a.sql", "1_b.sql"},
+		},
+	}
+	for _, tt := range tests {
+		files := append([]string(nil), tt.files...)
+		sortMigrations(files)
+		if !reflect.DeepEqual(files, tt.want) {
+			t.Errorf("sorted %q to %q, want %q", tt.files, files, tt.want)
+		}
+	}
+}
+
+// describeTable renders what the driver reports for a table, one line per column and key
+func describeTable(t *testing.T, d *offlineDriver, schema, table string) []string {
+	t.Helper()
+
+	columns, err := d.Columns(schema, table, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, c := range columns {
+		line := "column " + c.Name + " " + c.DBType
+		if c.ArrType != nil {
+			line += "(" + *c.ArrType + ")"
+		}
+		if c.Nullable {
+			line += " null"
+		} else {
+			line += " not null"
+		}
+		if c.Default != "" {
+			line += " default " + c.Default
+		}
+		if c.Unique {
+			line += " unique"
+		}
+		if c.Comment != "" {
+			line += " comment '" + c.Comment + "'"
+		}
+		lines = append(lines, line)
+	}
+
+	pkey, err := d.PrimaryKeyInfo(schema, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkey != nil {
+		lines = append(lines, fmt.Sprintf("pkey %s (%s)", pkey.Name, strings.Join(pkey.Columns, ", ")))
+	}
+	fkeys, err := d.ForeignKeyInfo(schema, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fk := range fkeys {
+		lines = append(lines, fmt.Sprintf("fkey %s %s -> %s.%s", fk.Name, fk.Column, fk.ForeignTable, fk.ForeignColumn))
+	}
+	for _, key := range d.UniqueKeys(schema, table) {
+		lines = append(lines, "unique ("+strings.Join(key, ", ")+")")
+	}
+	return lines
+}
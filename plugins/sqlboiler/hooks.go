@@ -0,0 +1,49 @@
+package sqlboiler
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/frankie-seb/sinatra/internal"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/v4/boilingcore"
+)
+
+// writeInsertHooks writes methods which run the insert hooks of the models next to the models, sqlboiler
+// only runs them in Insert and the batch creates insert multiple rows with one query
+func writeInsertHooks(state *boilingcore.State) error {
+	args := "ctx, exec"
+	if state.Config.NoContext {
+		args = "exec"
+	}
+
+	b := &bytes.Buffer{}
+	b.WriteString("// Code generated by Frankie Health Generator, DO NOT EDIT.\n\n")
+	b.WriteString("package " + state.Config.PkgName + "\n\n")
+	b.WriteString("import (\n\t\"context\"\n\n\t\"github.com/volatiletech/sqlboiler/v4/boil\"\n)\n")
+	for _, table := range state.Tables {
+		if table.IsJoinTable {
+			continue
+		}
+		model := state.Config.Aliases.Table(table.Name).UpSingular
+		for _, hook := range []string{"BeforeInsert", "AfterInsert"} {
+			fmt.Fprintf(b, "\n// Run%[2]sHooks runs the %[2]s hooks of the %[1]s like Insert does\n", model, hook)
+			fmt.Fprintf(b, "func (o *%s) Run%sHooks(ctx context.Context, exec boil.ContextExecutor) error {\n", model, hook)
+			if state.Config.NoHooks {
+				b.WriteString("\treturn nil\n}\n")
+				continue
+			}
+			fmt.Fprintf(b, "\treturn o.do%sHooks(%s)\n}\n", hook, args)
+		}
+	}
+
+	content, err := format.Source(b.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "could not format insert hooks")
+	}
+	fileName := filepath.Join(state.Config.OutFolder, internal.InsertHooksFileName)
+	return errors.Wrap(ioutil.WriteFile(fileName, content, 0o644), "could not write insert hooks") //nolint:gosec
+}
@@ -0,0 +1,45 @@
+package sqlboiler
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+
+	"github.com/frankie-seb/sinatra/internal"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/v4/boilingcore"
+)
+
+// writeJoinTables writes the to-many relationships of the tables which go through a join table next to
+// the models, sqlboiler only generates the methods which link them for those relationships and the
+// many-to-many mutations use them
+func writeJoinTables(state *boilingcore.State) error {
+	b := &bytes.Buffer{}
+	b.WriteString("// Code generated by Frankie Health Generator, DO NOT EDIT.\n\n")
+	b.WriteString("package " + state.Config.PkgName + "\n\n")
+	b.WriteString("// TableJoinRelationships are the to-many relationships of the tables which go through a join table\n")
+	b.WriteString("var TableJoinRelationships = []struct {\n\tTable        string\n\tForeignTable string\n\tJoinTable    string\n}{\n")
+	for _, table := range state.Tables {
+		if table.IsJoinTable {
+			continue
+		}
+		for _, rel := range table.ToManyRelationships {
+			if !rel.ToJoinTable {
+				continue
+			}
+			fmt.Fprintf(b, "\t{Table: %s, ForeignTable: %s, JoinTable: %s},\n",
+				strconv.Quote(table.Name), strconv.Quote(rel.ForeignTable), strconv.Quote(rel.JoinTable))
+		}
+	}
+	b.WriteString("}\n")
+
+	content, err := format.Source(b.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "could not format join tables")
+	}
+	fileName := filepath.Join(state.Config.OutFolder, internal.JoinTablesFileName)
+	return errors.Wrap(ioutil.WriteFile(fileName, content, 0o644), "could not write join tables") //nolint:gosec
+}
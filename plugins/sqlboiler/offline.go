@@ -0,0 +1,311 @@
+package sqlboiler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/frankie-seb/sinatra/internal"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/v4/drivers"
+	psqldriver "github.com/volatiletech/sqlboiler/v4/drivers/sqlboiler-psql/driver"
+)
+
+// offlineDriverName is the sqlboiler driver which reads the tables from sql files
+const offlineDriverName = "sinatra-ddl"
+
+// configDDL is the driver config key of the sql file or migrations directory
+const configDDL = "ddl"
+
+func init() { //nolint:gochecknoinits
+	drivers.RegisterFromInit(offlineDriverName, &offlineDriver{})
+}
+
+// offlineDriver builds the postgres table information from a pg_dump --schema-only file or a
+// directory of migrations instead of a live database. Templates, imports and the type mapping
+// are the ones of the psql driver so the generated models are the same.
+type offlineDriver struct {
+	psqldriver.PostgresDriver
+	ddl *ddlSchema
+}
+
+func (d *offlineDriver) Assemble(config drivers.Config) (dbinfo *drivers.DBInfo, err error) {
+	defer func() {
+		if r := recover(); r != nil && err == nil {
+			// the config helpers of sqlboiler panic with errors, anything else is wrapped
+			dbinfo = nil
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	source := config.MustString(configDDL)
+	schema := config.DefaultString(drivers.ConfigSchema, "public")
+	whitelist, _ := config.StringSlice(drivers.ConfigWhitelist)
+	blacklist, _ := config.StringSlice(drivers.ConfigBlacklist)
+
+	if d.ddl, err = loadDDL(source, schema); err != nil {
+		return nil, err
+	}
+
+	dbinfo = &drivers.DBInfo{
+		Schema: schema,
+		Dialect: drivers.Dialect{
+			LQ: '"',
+			RQ: '"',
+
+			UseIndexPlaceholders: true,
+			UseSchema:            schema != "public",
+			UseDefaultKeyword:    true,
+		},
+	}
+	dbinfo.Tables, err = drivers.Tables(d, schema, whitelist, blacklist)
+	if err != nil {
+		return nil, err
+	}
+	return dbinfo, nil
+}
+
+func (d *offlineDriver) TableNames(schema string, whitelist, blacklist []string) ([]string, error) {
+	whitelist = drivers.TablesFromList(whitelist)
+	blacklist = drivers.TablesFromList(blacklist)
+
+	var names []string
+	for _, t := range d.ddl.tables {
+		if t.schema != schema {
+			continue
+		}
+		if len(whitelist) > 0 && !internal.SliceContains(whitelist, t.name) {
+			continue
+		}
+		if len(whitelist) == 0 && internal.SliceContains(blacklist, t.name) {
+			continue
+		}
+		names = append(names, t.name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (d *offlineDriver) Columns(schema, tableName string, whitelist, blacklist []string) ([]drivers.Column, error) {
+	t := d.ddl.table(schema, tableName)
+	if t == nil {
+		return nil, fmt.Errorf("table %s does not exist", tableName)
+	}
+	whitelist = drivers.ColumnsFromList(whitelist, tableName)
+	blacklist = drivers.ColumnsFromList(blacklist, tableName)
+
+	var columns []drivers.Column
+	for _, c := range t.columns {
+		if c.generated {
+			continue
+		}
+		if len(whitelist) > 0 && !internal.SliceContains(whitelist, c.name) {
+			continue
+		}
+		if len(whitelist) == 0 && internal.SliceContains(blacklist, c.name) {
+			continue
+		}
+
+		column := drivers.Column{
+			Name:       c.name,
+			DBType:     c.dataType,
+			FullDBType: c.udtName,
+			ArrType:    c.arrType,
+			UDTName:    c.udtName,
+			Default:    c.def,
+			Comment:    c.comment,
+			Nullable:   c.nullable,
+			Unique:     d.isUnique(t, c.name),
+		}
+		// enums are reported like the psql driver does: enum.name('first','second')
+		if labels, ok := d.ddl.enums[qualifiedName(c.typeSchema, c.udtName)]; ok && c.dataType == "USER-DEFINED" {
+			column.DBType = fmt.Sprintf("enum.%s('%s')", c.udtName, strings.Join(labels, "','"))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// isUnique reports whether the column has a unique constraint or unique index on its own
+func (d *offlineDriver) isUnique(t *ddlTable, column string) bool {
+	if t.pkey != nil && len(t.pkey.columns) == 1 && t.pkey.columns[0] == column {
+		return true
+	}
+	for _, u := range t.uniques {
+		if len(u.columns) == 1 && u.columns[0] == column {
+			return true
+		}
+	}
+	for _, idx := range d.ddl.indexes {
+		if idx.unique && idx.schema == t.schema && idx.table == t.name && len(idx.columns) == 1 && idx.columns[0] == column {
+			return true
+		}
+	}
+	return false
+}
+
+// UniqueKeys returns the columns of the unique constraints and unique indexes of the table
+func (d *offlineDriver) UniqueKeys(schema, tableName string) [][]string {
+	t := d.ddl.table(schema, tableName)
+	if t == nil {
+		return nil
+	}
+	var keys [][]string
+	for _, u := range t.uniques {
+		keys = append(keys, u.columns)
+	}
+	// the indexes are a map, sort them for a stable output
+	var names []string
+	for name, idx := range d.ddl.indexes {
+		if idx.unique && idx.schema == t.schema && idx.table == t.name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		keys = append(keys, d.ddl.indexes[name].columns)
+	}
+	return keys
+}
+
+func (d *offlineDriver) PrimaryKeyInfo(schema, tableName string) (*drivers.PrimaryKey, error) {
+	t := d.ddl.table(schema, tableName)
+	if t == nil || t.pkey == nil {
+		return nil, nil
+	}
+	return &drivers.PrimaryKey{
+		Name:    t.pkey.name,
+		Columns: append([]string(nil), t.pkey.columns...),
+	}, nil
+}
+
+func (d *offlineDriver) ForeignKeyInfo(schema, tableName string) ([]drivers.ForeignKey, error) {
+	t := d.ddl.table(schema, tableName)
+	if t == nil {
+		return nil, nil
+	}
+
+	var fkeys []drivers.ForeignKey
+	for _, fk := range t.fkeys {
+		if fk.foreignSchema != schema {
+			continue
+		}
+		foreignColumns := fk.foreignColumns
+		// without a column list the primary key of the referenced table is used
+		if len(foreignColumns) == 0 {
+			if foreign := d.ddl.table(fk.foreignSchema, fk.foreignTable); foreign != nil && foreign.pkey != nil {
+				foreignColumns = foreign.pkey.columns
+			}
+		}
+		if len(foreignColumns) != len(fk.columns) {
+			return nil, fmt.Errorf("foreign key %s of %s references an unknown key of %s", fk.name, tableName, fk.foreignTable)
+		}
+
+		for i, column := range fk.columns {
+			fkeys = append(fkeys, drivers.ForeignKey{
+				Table:         tableName,
+				Name:          fk.name,
+				Column:        column,
+				ForeignTable:  fk.foreignTable,
+				ForeignColumn: foreignColumns[i],
+			})
+		}
+	}
+	return fkeys, nil
+}
+
+// migrationMarkers are the up/down markers of sql-migrate, goose and dbmate
+var migrationMarkers = map[string]bool{
+	"+migrate up":   true,
+	"+migrate down": false,
+	"+goose up":     true,
+	"+goose down":   false,
+	"migrate:up":    true,
+	"migrate:down":  false,
+}
+
+// loadDDL applies a sql file, or all .sql files of a directory in migration order, to a new schema.
+// Down migrations, *.down.sql files or the down sections of a file, are left out.
+func loadDDL(source string, defaultSchema string) (*ddlSchema, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read ddl source")
+	}
+
+	files := []string{source}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(source, "*.sql")); err != nil {
+			return nil, err
+		}
+		sortMigrations(files)
+	}
+
+	ddl := newDDLSchema(defaultSchema)
+	for _, file := range files {
+		if strings.HasSuffix(file, ".down.sql") {
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s", file)
+		}
+		if err := ddl.apply(upMigration(string(content))); err != nil {
+			return nil, errors.Wrapf(err, "could not parse %s", file)
+		}
+	}
+	return ddl, nil
+}
+
+// sortMigrations orders migration files by their numeric version prefix, 2_users.sql runs before
+// 10_posts.sql. Files without a version, or with the same version, are ordered by name.
+func sortMigrations(files []string) {
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := filepath.Base(files[i]), filepath.Base(files[j])
+		va, vb := migrationVersion(a), migrationVersion(b)
+		if va != vb {
+			// versions can be longer than an int64, compare them as numbers without leading zeros
+			if len(va) != len(vb) {
+				return len(va) < len(vb)
+			}
+			return va < vb
+		}
+		return a < b
+	})
+}
+
+// migrationVersion returns the leading digits of a file name without leading zeros
+func migrationVersion(name string) string {
+	end := 0
+	for end < len(name) && isDigit(name[end]) {
+		end++
+	}
+	return strings.TrimLeft(name[:end], "0")
+}
+
+// upMigration strips the down sections of a migration file, files without markers are kept as is
+func upMigration(content string) string {
+	var b strings.Builder
+	up := true
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "--") {
+			marker := strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(trimmed, "--")), " "))
+			for m, isUp := range migrationMarkers {
+				if marker == m || strings.HasPrefix(marker, m+" ") {
+					up = isUp
+				}
+			}
+		}
+		if up {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
@@ -1,9 +1,14 @@
 package sqlboiler
 
 import (
+	"fmt"
+	"os/exec"
+
 	"github.com/frankie-seb/sinatra/internal"
 	"github.com/pkg/errors"
 	"github.com/volatiletech/sqlboiler/v4/boilingcore"
+	"github.com/volatiletech/sqlboiler/v4/drivers"
+	_ "github.com/volatiletech/sqlboiler/v4/drivers/sqlboiler-mysql/driver"
 	_ "github.com/volatiletech/sqlboiler/v4/drivers/sqlboiler-psql/driver"
 	"github.com/volatiletech/sqlboiler/v4/importers"
 )
@@ -13,16 +18,37 @@ var (
 	cmdConfig *boilingcore.Config
 )
 
+// driverConfigs builds the sqlboiler driver config per supported database driver
+var driverConfigs = map[string]func(cfg *internal.Config) (map[string]interface{}, error){
+	internal.DriverPsql:   getPsqlDriverConfig,
+	internal.DriverMysql:  getMysqlDriverConfig,
+	internal.DriverSqlite: getSqliteDriverConfig,
+}
+
+// sqliteDriverBinary is the sqlboiler sqlite driver, it is not part of sqlboiler and can't be
+// linked in (it needs cgo) so it's used as binary driver like the sqlboiler cli does.
+// Install it with: go install github.com/volatiletech/sqlboiler-sqlite3@latest
+const sqliteDriverBinary = "sqlboiler-sqlite3"
+
+var sqliteDriverRegistered bool
+
 func Run(cfg *internal.Config) error {
-	// Get the configuration for the driver.
-	driverConfig, err := getPsqlDriverConfig(cfg)
+	// Get the configuration for the driver, with a ddl source the tables are read from sql files
+	// instead of the database.
+	driverName := cfg.Database.DBDriver
+	getConfig := getDriverConfig
+	if cfg.Database.DDL != "" {
+		driverName = offlineDriverName
+		getConfig = getOfflineDriverConfig
+	}
+	driverConfig, err := getConfig(cfg)
 	if err != nil {
 		return errors.Wrap(err, "failed to create driver config")
 	}
 
 	// Create the configurations from flags.
 	cmdConfig = &boilingcore.Config{
-		DriverName:       cfg.Database.DBDriver,
+		DriverName:       driverName,
 		DriverConfig:     driverConfig,
 		OutFolder:        cfg.Model.DirName,
 		PkgName:          cfg.Model.Package,
@@ -37,10 +63,10 @@ func Run(cfg *internal.Config) error {
 		AddSoftDeletes:   cfg.Database.AddSoftDeletes,
 		Wipe:             cfg.Database.Wipe,
 		StructTagCasing:  cfg.Database.StructTagCasing, // camel | snake
-		// TemplateDirs:     getStringSliceP(flags, "templates"),
-		// Tags:             getStringSliceP(flags, "tag"),
-		// Replacements:     getStringSliceP(flags, "replace"),
-		Imports: importers.NewDefaultImports(),
+		TemplateDirs:     cfg.Templates.Sqlboiler.Dirs,
+		Tags:             cfg.Templates.Sqlboiler.Tags,
+		Replacements:     cfg.Templates.Sqlboiler.Replacements,
+		Imports:          importers.NewDefaultImports(),
 	}
 
 	// Run SQL Boiler.
@@ -52,10 +78,49 @@ func Run(cfg *internal.Config) error {
 	if err != nil {
 		return err
 	}
+	keys, err := uniqueKeys(cmdState, driverName, driverConfig)
+	if err != nil {
+		return err
+	}
+	if err := writeUniqueKeys(cmdState, keys); err != nil {
+		return err
+	}
+	if err := writeJoinTables(cmdState); err != nil {
+		return err
+	}
+	if err := writeInsertHooks(cmdState); err != nil {
+		return err
+	}
 	return cmdState.Cleanup()
 
 }
 
+func getDriverConfig(cfg *internal.Config) (map[string]interface{}, error) {
+	build, ok := driverConfigs[cfg.Database.DBDriver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Database.DBDriver)
+	}
+	return build(cfg)
+}
+
+func getOfflineDriverConfig(cfg *internal.Config) (map[string]interface{}, error) {
+	if cfg.Database.DBDriver != internal.DriverPsql {
+		return nil, fmt.Errorf("generating from sql files is only supported for %s, not %s", internal.DriverPsql, cfg.Database.DBDriver)
+	}
+
+	config := map[string]interface{}{
+		configDDL:   cfg.Database.DDL,
+		"blacklist": cfg.Database.Blacklist,
+		"whitelist": cfg.Database.Whitelist,
+	}
+
+	if cfg.Database.Schema != "" {
+		config["schema"] = cfg.Database.Schema
+	}
+
+	return config, nil
+}
+
 func getPsqlDriverConfig(cfg *internal.Config) (map[string]interface{}, error) {
 	config := map[string]interface{}{
 		"dbname":    cfg.Database.DBName,
@@ -74,3 +139,49 @@ func getPsqlDriverConfig(cfg *internal.Config) (map[string]interface{}, error) {
 
 	return config, nil
 }
+
+func getMysqlDriverConfig(cfg *internal.Config) (map[string]interface{}, error) {
+	// MySQL has no schemas within a database, the database name is the schema
+	if cfg.Database.Schema != "" && cfg.Database.Schema != cfg.Database.DBName {
+		return nil, errors.New("mysql does not support a schema other than the database name")
+	}
+
+	config := map[string]interface{}{
+		"dbname":         cfg.Database.DBName,
+		"host":           cfg.Database.Host,
+		"port":           cfg.Database.Port,
+		"user":           cfg.Database.User,
+		"pass":           cfg.Database.Password,
+		"blacklist":      cfg.Database.Blacklist,
+		"whitelist":      cfg.Database.Whitelist,
+		"tinyint_as_int": cfg.Database.TinyintAsInt,
+	}
+
+	// the mysql driver expects true, false or skip-verify and defaults to true
+	if cfg.Database.SSLMode != "" {
+		config["sslmode"] = cfg.Database.SSLMode
+	}
+
+	return config, nil
+}
+
+func getSqliteDriverConfig(cfg *internal.Config) (map[string]interface{}, error) {
+	if cfg.Database.DBName == "" {
+		return nil, errors.New("sqlite3 needs the path of the database file as dbname")
+	}
+
+	if !sqliteDriverRegistered {
+		path, err := exec.LookPath(sqliteDriverBinary)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find the %s driver, install it with go install github.com/volatiletech/sqlboiler-sqlite3@latest", sqliteDriverBinary)
+		}
+		drivers.RegisterBinary(internal.DriverSqlite, path)
+		sqliteDriverRegistered = true
+	}
+
+	return map[string]interface{}{
+		"dbname":    cfg.Database.DBName,
+		"blacklist": cfg.Database.Blacklist,
+		"whitelist": cfg.Database.Whitelist,
+	}, nil
+}
@@ -0,0 +1,217 @@
+package sqlboiler
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/frankie-seb/sinatra/internal"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/v4/boilingcore"
+	"github.com/volatiletech/sqlboiler/v4/drivers"
+	mysqldriver "github.com/volatiletech/sqlboiler/v4/drivers/sqlboiler-mysql/driver"
+	psqldriver "github.com/volatiletech/sqlboiler/v4/drivers/sqlboiler-psql/driver"
+)
+
+// uniqueKeysDriver knows the unique constraints and unique indexes of the tables, including the ones
+// over multiple columns
+type uniqueKeysDriver interface {
+	UniqueKeys(schema, tableName string) [][]string
+}
+
+// uniqueKeysQuery reads the unique keys of a live database, the query returns the table, index and
+// column names ordered by table, index and the position of the column in the index
+type uniqueKeysQuery struct {
+	sqlDriver string
+	connStr   func(config drivers.Config) string
+	query     string
+}
+
+// uniqueKeysQueries are the queries of the live databases. Unique constraints are backed by a unique
+// index, so the indexes cover both. Partial and expression indexes can't be a conflict target and
+// are left out, as are the included columns of a covering index (postgres 11 and later).
+var uniqueKeysQueries = map[string]uniqueKeysQuery{
+	internal.DriverPsql: {
+		sqlDriver: "postgres",
+		connStr: func(config drivers.Config) string {
+			return psqldriver.PSQLBuildQueryString(
+				config.DefaultString(drivers.ConfigUser, ""),
+				config.DefaultString(drivers.ConfigPass, ""),
+				config.DefaultString(drivers.ConfigDBName, ""),
+				config.DefaultString(drivers.ConfigHost, ""),
+				config.DefaultInt(drivers.ConfigPort, 5432),
+				config.DefaultString(drivers.ConfigSSLMode, "require"),
+			)
+		},
+		query: `select t.relname, i.relname, a.attname
+			from pg_index x
+			join pg_class i on i.oid = x.indexrelid
+			join pg_class t on t.oid = x.indrelid
+			join pg_namespace n on n.oid = t.relnamespace
+			cross join lateral unnest(x.indkey::int2[]) with ordinality as k(attnum, ord)
+			join pg_attribute a on a.attrelid = t.oid and a.attnum = k.attnum
+			where n.nspname = $1 and x.indisunique and not x.indisprimary
+				and x.indpred is null and x.indexprs is null and k.ord <= x.indnkeyatts
+			order by t.relname, i.relname, k.ord`,
+	},
+	internal.DriverMysql: {
+		sqlDriver: "mysql",
+		connStr: func(config drivers.Config) string {
+			return mysqldriver.MySQLBuildQueryString(
+				config.DefaultString(drivers.ConfigUser, ""),
+				config.DefaultString(drivers.ConfigPass, ""),
+				config.DefaultString(drivers.ConfigDBName, ""),
+				config.DefaultString(drivers.ConfigHost, ""),
+				config.DefaultInt(drivers.ConfigPort, 3306),
+				config.DefaultString(drivers.ConfigSSLMode, "true"),
+			)
+		},
+		// the columns of functional indexes have no name
+		query: `select table_name, index_name, column_name
+			from information_schema.statistics
+			where table_schema = ? and non_unique = 0 and index_name <> 'PRIMARY'
+			order by table_name, index_name, seq_in_index`,
+	},
+}
+
+// databaseUniqueKeys are the unique keys of a live database by table name
+type databaseUniqueKeys map[string][][]string
+
+func (k databaseUniqueKeys) UniqueKeys(schema, tableName string) [][]string {
+	return k[tableName]
+}
+
+// uniqueKeys returns the unique keys of the tables, nil when the driver can't tell them and only the
+// columns which are unique on their own are known
+func uniqueKeys(state *boilingcore.State, driverName string, config drivers.Config) (uniqueKeysDriver, error) {
+	if d, ok := state.Driver.(uniqueKeysDriver); ok {
+		return d, nil
+	}
+	q, ok := uniqueKeysQueries[driverName]
+	if !ok {
+		return nil, nil
+	}
+	// mysql has no schemas, the database is the schema
+	schema := state.Schema
+	if schema == "" {
+		schema = config.DefaultString(drivers.ConfigDBName, "")
+	}
+
+	conn, err := sql.Open(q.sqlDriver, q.connStr(config))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not connect to read the unique keys")
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query(q.query, schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the unique keys")
+	}
+	defer rows.Close()
+
+	keys := databaseUniqueKeys{}
+	var lastTable, lastIndex string
+	var key []string
+	expression := false
+	add := func() {
+		if key != nil && !expression {
+			keys[lastTable] = append(keys[lastTable], key)
+		}
+	}
+	for rows.Next() {
+		var table, index string
+		var column sql.NullString
+		if err := rows.Scan(&table, &index, &column); err != nil {
+			return nil, errors.Wrap(err, "could not read the unique keys")
+		}
+		if table != lastTable || index != lastIndex {
+			add()
+			lastTable, lastIndex, key, expression = table, index, nil, false
+		}
+		// a functional index, it's not a key over columns
+		if !column.Valid {
+			expression = true
+			continue
+		}
+		key = append(key, column.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read the unique keys")
+	}
+	add()
+	return keys, nil
+}
+
+// writeUniqueKeys writes the unique keys of the tables next to the models, sqlboiler does not keep
+// them in the models and the upsert mutations use them as conflict targets
+func writeUniqueKeys(state *boilingcore.State, keys uniqueKeysDriver) error {
+	b := &bytes.Buffer{}
+	b.WriteString("// Code generated by Frankie Health Generator, DO NOT EDIT.\n\n")
+	b.WriteString("package " + state.Config.PkgName + "\n\n")
+	b.WriteString("// TableUniqueKeys are the columns of the unique constraints and unique indexes per table\n")
+	b.WriteString("var TableUniqueKeys = []struct {\n\tTable   string\n\tColumns []string\n}{\n")
+	for _, table := range state.Tables {
+		if table.IsJoinTable {
+			continue
+		}
+		for _, key := range tableUniqueKeys(state, keys, table) {
+			quoted := make([]string, len(key))
+			for i, c := range key {
+				quoted[i] = strconv.Quote(c)
+			}
+			fmt.Fprintf(b, "\t{Table: %s, Columns: []string{%s}},\n", strconv.Quote(table.Name), strings.Join(quoted, ", "))
+		}
+	}
+	b.WriteString("}\n")
+
+	content, err := format.Source(b.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "could not format unique keys")
+	}
+	fileName := filepath.Join(state.Config.OutFolder, internal.UniqueKeysFileName)
+	return errors.Wrap(ioutil.WriteFile(fileName, content, 0o644), "could not write unique keys") //nolint:gosec
+}
+
+// tableUniqueKeys returns the unique keys of the table without the primary key, without unique keys of
+// the driver (sqlite) the columns which are unique on their own are used
+func tableUniqueKeys(state *boilingcore.State, uniqueKeys uniqueKeysDriver, table drivers.Table) [][]string {
+	var keys [][]string
+	if uniqueKeys != nil {
+		keys = uniqueKeys.UniqueKeys(state.Schema, table.Name)
+	} else {
+		for _, c := range table.Columns {
+			if c.Unique {
+				keys = append(keys, []string{c.Name})
+			}
+		}
+	}
+
+	var a [][]string
+	seen := map[string]bool{}
+	for _, key := range keys {
+		id := strings.Join(key, ",")
+		if seen[id] || (table.PKey != nil && sameColumns(key, table.PKey.Columns)) {
+			continue
+		}
+		seen[id] = true
+		a = append(a, key)
+	}
+	return a
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
@@ -0,0 +1,200 @@
+// Package sinatra generates a graphql api on top of sqlboiler and gqlgen from a database. Generate runs
+// the same pipeline as the sinatra command, the command itself lives in cmd/sinatra.
+package sinatra
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/api"
+	"github.com/99designs/gqlgen/plugin"
+	"github.com/frankie-seb/sinatra/internal"
+	"github.com/frankie-seb/sinatra/plugins/helpers"
+	"github.com/frankie-seb/sinatra/plugins/resolvers"
+	"github.com/frankie-seb/sinatra/plugins/schema"
+	"github.com/frankie-seb/sinatra/plugins/sqlboiler"
+	"github.com/pkg/errors"
+)
+
+// Config is the content of sinatra.yml
+type Config = internal.Config
+
+// LoadConfig loads the config file and layers the profile over it, profile can be empty
+func LoadConfig(filename string, profile string) (*Config, error) {
+	return internal.LoadConfig(filename, profile)
+}
+
+// LoadConfigFromDefaultLocations looks for the config in the current directory and its parents
+func LoadConfigFromDefaultLocations(profile string) (*Config, error) {
+	return internal.LoadConfigFromDefaultLocations(profile)
+}
+
+// Stage is a step of the generation pipeline
+type Stage uint8
+
+const (
+	// StageModels generates the database models with sqlboiler
+	StageModels Stage = 1 << iota
+	// StageSchema generates the graphql schema from the database models
+	StageSchema
+	// StageGraph runs gqlgen together with the helper and resolver plugins
+	StageGraph
+
+	StagesAll = StageModels | StageSchema | StageGraph
+)
+
+func (s Stage) String() string {
+	var names []string
+	for _, st := range []struct {
+		stage Stage
+		name  string
+	}{
+		{StageModels, "models"},
+		{StageSchema, "schema"},
+		{StageGraph, "graph"},
+	} {
+		if s&st.stage != 0 {
+			names = append(names, st.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// GenerateError is a single failure of a generate run, it names the file, template position, model and
+// field when they are known
+type GenerateError = internal.GenerateError
+
+// StageError is returned by Generate when a stage failed, Errors holds every failure of the stage
+type StageError struct {
+	Stage  Stage
+	Errors []*GenerateError
+}
+
+func (e *StageError) Error() string {
+	lines := []string{e.Stage.String() + " stage failed:"}
+	for _, err := range e.Errors {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newStageError collects the failures of the stage from the report returned by the generators, other
+// errors are split into a failure per line
+func newStageError(stage Stage, err error) *StageError {
+	var errs []*GenerateError
+	var report *internal.Report
+	if errors.As(err, &report) {
+		errs = report.Errors
+	} else {
+		errs = internal.PositionErrors(err)
+	}
+	for _, e := range errs {
+		e.Stage = stage.String()
+	}
+	return &StageError{Stage: stage, Errors: errs}
+}
+
+type options struct {
+	stages              Stage
+	hooks               *schema.HooksConfig
+	authorizationScopes []*resolvers.AuthorizationScope
+	plugins             []plugin.Plugin
+}
+
+// Option changes how Generate runs
+type Option func(o *options)
+
+// WithStages only runs the given stages, by default all of them run
+func WithStages(stages Stage) Option {
+	return func(o *options) {
+		o.stages = stages
+	}
+}
+
+// WithHooks changes the generated schema with the given hooks
+func WithHooks(hooks *schema.HooksConfig) Option {
+	return func(o *options) {
+		o.hooks = hooks
+	}
+}
+
+// WithAuthorizationScopes adds the scopes to the where clauses and inputs of the generated resolvers
+func WithAuthorizationScopes(scopes ...*resolvers.AuthorizationScope) Option {
+	return func(o *options) {
+		o.authorizationScopes = append(o.authorizationScopes, scopes...)
+	}
+}
+
+// AddPlugin runs the gqlgen plugin after the sinatra plugins
+func AddPlugin(p plugin.Plugin) Option {
+	return func(o *options) {
+		o.plugins = append(o.plugins, p)
+	}
+}
+
+// Generate runs the generation pipeline in the current directory, generated files whose inputs did not
+// change since the last successful run are not rewritten. The failures of a stage are returned as a
+// *StageError, the following stages are not run.
+func Generate(ctx context.Context, cfg *Config, opts ...Option) error {
+	o := &options{
+		stages: StagesAll,
+		hooks:  &schema.HooksConfig{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.stages&StageModels != 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := sqlboiler.Run(cfg); err != nil {
+			return newStageError(StageModels, err)
+		}
+	}
+
+	cache := internal.LoadCache(internal.CacheFile)
+
+	// Generate the schema
+	if o.stages&StageSchema != 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := schema.SchemaWrite(cfg, o.hooks, cache); err != nil {
+			return newStageError(StageSchema, err)
+		}
+	}
+
+	if o.stages&StageGraph != 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Generate the gqlgen config
+		gqlcfg, err := internal.LoadGqlgenConfig(cfg)
+		if err != nil {
+			return newStageError(StageGraph, errors.Wrap(err, "error while trying to generate the config"))
+		}
+
+		// Run generator
+		apiOptions := []api.Option{
+			api.AddPlugin(helpers.NewHelperPlugin(
+				cfg,
+				cache,
+			)),
+			api.AddPlugin(resolvers.NewResolverPlugin(
+				cfg,
+				cache,
+				o.authorizationScopes...,
+			)),
+		}
+		for _, p := range o.plugins {
+			apiOptions = append(apiOptions, api.AddPlugin(p))
+		}
+		if err = api.Generate(gqlcfg, apiOptions...); err != nil {
+			return newStageError(StageGraph, err)
+		}
+	}
+
+	return cache.Save()
+}
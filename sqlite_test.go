@@ -0,0 +1,93 @@
+//go:build integration
+// +build integration
+
+package sinatra_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestGeneratedResolversOnSQLite generates the project in testdata/sqlite from a SQLite database and runs its
+// tests, which query the generated resolvers through the graphql handler. It needs cgo and the
+// sqlboiler-sqlite3 driver and only runs with the integration build tag.
+func TestGeneratedResolversOnSQLite(t *testing.T) {
+	if _, err := exec.LookPath("sqlboiler-sqlite3"); err != nil {
+		t.Skip("sqlboiler-sqlite3 is not installed, install it with go install github.com/volatiletech/sqlboiler-sqlite3@latest")
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	copyDir(t, filepath.Join("testdata", "sqlite"), dir)
+
+	// the project is generated with this checkout of sinatra
+	goMod, err := os.OpenFile(filepath.Join(dir, "go.mod"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = goMod.WriteString("\nreplace github.com/frankie-seb/sinatra => " + root + "\n")
+	if cerr := goMod.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := filepath.Join(dir, "dev.db")
+	goRun(t, dir, "run", "./migrate", db, "migrations")
+	goRun(t, dir, "run", "github.com/frankie-seb/sinatra/cmd/sinatra", "generate")
+	goRun(t, dir, "vet", "./...")
+	goRun(t, dir, "test", "./resolvers")
+}
+
+// goRun runs the go command of the toolchain the test was built with in dir, the generator loads the
+// packages with the same toolchain
+func goRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	bin := filepath.Join(runtime.GOROOT(), "bin")
+	cmd := exec.Command(filepath.Join(bin, "go"), args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"GOFLAGS=-mod=mod",
+		"SQLITE_DB="+filepath.Join(dir, "dev.db"),
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go %v: %v\n%s", args, err, out)
+	}
+}
+
+func copyDir(t *testing.T, from, to string) {
+	t.Helper()
+
+	err := filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, content, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,9 @@
+// Package templates embeds the templates sinatra renders the helpers and resolvers with
+package templates
+
+import "embed"
+
+// FS holds the default templates
+//
+//go:embed *.gotpl
+var FS embed.FS
@@ -0,0 +1,38 @@
+// Command migrate applies the migrations to a new SQLite database, the models are generated from it
+package main
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		log.Fatal("usage: migrate <database file> <migrations dir>")
+	}
+
+	db, err := sql.Open("sqlite3", os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	files, err := filepath.Glob(filepath.Join(os.Args[2], "*.sql"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if _, err := db.Exec(string(content)); err != nil {
+			log.Fatalf("%s: %v", file, err)
+		}
+	}
+}
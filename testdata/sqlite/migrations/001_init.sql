@@ -0,0 +1,13 @@
+-- integer primary keys are declared NOT NULL, SQLite reports them as nullable otherwise
+CREATE TABLE users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  email TEXT NOT NULL UNIQUE,
+  name TEXT
+);
+
+CREATE TABLE posts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  user_id INTEGER REFERENCES users (id),
+  title TEXT NOT NULL DEFAULT '',
+  score INTEGER NOT NULL DEFAULT 0
+);
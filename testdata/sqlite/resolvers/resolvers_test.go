@@ -0,0 +1,218 @@
+package resolvers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/frankie-seb/sinatra/middleware"
+	_ "github.com/mattn/go-sqlite3"
+
+	"example.com/sqlite/graph"
+)
+
+type post struct {
+	ID    string
+	Title string
+	Score int
+}
+
+// newClient serves the generated resolvers like the scaffolded server does, every request runs in a
+// transaction which is committed when the response has no errors
+func newClient(t *testing.T) *client.Client {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", os.Getenv("SQLITE_DB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: New()}))
+	// the client posts to /
+	h := middleware.TransactionHandler(db, []string{"/"})(srv)
+	return client.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "db", db))) //nolint:staticcheck
+	}))
+}
+
+func TestResolvers(t *testing.T) {
+	c := newClient(t)
+
+	var user struct {
+		CreateUser struct{ User struct{ ID string } }
+	}
+	c.MustPost(`mutation { createUser(input: {email: "frank@example.com", name: "Frank"}) { user { id } } }`, &user)
+	userID := user.CreateUser.User.ID
+
+	var created struct {
+		CreatePosts struct{ Posts []post }
+	}
+	c.MustPost(`mutation($userId: ID!) {
+		createPosts(input: {posts: [
+			{userId: $userId, title: "a*c", score: 1},
+			{userId: $userId, title: "abc", score: 2},
+			{userId: $userId, title: "a?c", score: 3}
+		]}) { posts { id title score } }
+	}`, &created, client.Var("userId", userID))
+	posts := created.CreatePosts.Posts
+	if len(posts) != 3 {
+		t.Fatalf("created %d posts, want 3", len(posts))
+	}
+	for i, title := range []string{"a*c", "abc", "a?c"} {
+		if posts[i].Title != title {
+			t.Errorf("created post %d is %q, want %q", i, posts[i].Title, title)
+		}
+	}
+
+	t.Run("strict filters match glob characters literally", func(t *testing.T) {
+		for _, tt := range []struct {
+			where string
+			want  []string
+		}{
+			{`{title: {startWithStrict: "a*"}}`, []string{"a*c"}},
+			{`{title: {containStrict: "?"}}`, []string{"a?c"}},
+			{`{title: {endWithStrict: "[c"}}`, nil},
+			{`{title: {startWithStrict: "a"}}`, []string{"a*c", "abc", "a?c"}},
+			{`{title: {startWithStrict: "A"}}`, nil},
+		} {
+			var resp struct {
+				Posts struct{ Edges []struct{ Node post } }
+			}
+			c.MustPost(`{ posts(first: 10, filter: {where: `+tt.where+`}, ordering: [{sort: ID}]) { edges { node { title } } } }`, &resp)
+			var got []string
+			for _, e := range resp.Posts.Edges {
+				got = append(got, e.Node.Title)
+			}
+			if !equal(got, tt.want) {
+				t.Errorf("%s matched %q, want %q", tt.where, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		query := `query($after: String) {
+			posts(first: 2, after: $after, ordering: [{sort: SCORE, direction: DESC}]) {
+				edges { node { title } }
+				pageInfo { hasNextPage endCursor }
+			}
+		}`
+		var resp struct {
+			Posts struct {
+				Edges    []struct{ Node post }
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   *string
+				}
+			}
+		}
+		var got []string
+		var after *string
+		for page := 0; page < 3; page++ {
+			c.MustPost(query, &resp, client.Var("after", after))
+			for _, e := range resp.Posts.Edges {
+				got = append(got, e.Node.Title)
+			}
+			if !resp.Posts.PageInfo.HasNextPage {
+				break
+			}
+			after = resp.Posts.PageInfo.EndCursor
+		}
+		if want := []string{"a?c", "abc", "a*c"}; !equal(got, want) {
+			t.Errorf("paginated %q, want %q", got, want)
+		}
+	})
+
+	t.Run("default page", func(t *testing.T) {
+		var resp struct {
+			Posts struct {
+				Edges    []struct{ Node post }
+				PageInfo struct{ HasNextPage bool }
+			}
+		}
+		c.MustPost(`{ posts { edges { node { title } } pageInfo { hasNextPage } } }`, &resp)
+		if len(resp.Posts.Edges) != 3 || resp.Posts.PageInfo.HasNextPage {
+			t.Errorf("got %d posts and a next page %v, want all 3 posts", len(resp.Posts.Edges), resp.Posts.PageInfo.HasNextPage)
+		}
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		var resp struct {
+			PostAggregate []struct {
+				Count int
+				Sum   struct{ Score float64 }
+				Group struct{ UserID string }
+			}
+		}
+		c.MustPost(`{ postAggregate(groupBy: [USER_ID]) { count sum { score } group { userId } } }`, &resp)
+		if len(resp.PostAggregate) != 1 {
+			t.Fatalf("got %d groups, want 1", len(resp.PostAggregate))
+		}
+		if a := resp.PostAggregate[0]; a.Count != 3 || a.Sum.Score != 6 || a.Group.UserID != userID {
+			t.Errorf("got %+v, want 3 posts with a score of 6 of %s", a, userID)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		var resp struct {
+			UpdatePost struct{ Post post }
+		}
+		c.MustPost(`mutation($id: ID!) { updatePost(id: $id, input: {score: 10}) { post { title score } } }`, &resp, client.Var("id", posts[1].ID))
+		if p := resp.UpdatePost.Post; p.Title != "abc" || p.Score != 10 {
+			t.Errorf("updated %+v, want abc with a score of 10", p)
+		}
+	})
+
+	t.Run("nodes", func(t *testing.T) {
+		var resp struct {
+			Nodes []*struct {
+				ID    string
+				Title string
+			}
+		}
+		c.MustPost(`query($ids: [ID!]!) { nodes(ids: $ids) { id ... on Post { title } } }`, &resp,
+			client.Var("ids", []string{posts[2].ID, "unknown-1", userID}))
+		if len(resp.Nodes) != 3 {
+			t.Fatalf("got %d nodes, want 3", len(resp.Nodes))
+		}
+		if n := resp.Nodes[0]; n == nil || n.Title != "a?c" {
+			t.Errorf("first node is %+v, want a?c", n)
+		}
+		if resp.Nodes[1] != nil {
+			t.Errorf("node of an unknown type is %+v, want null", resp.Nodes[1])
+		}
+		if n := resp.Nodes[2]; n == nil || n.ID != userID {
+			t.Errorf("last node is %+v, want %s", n, userID)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		var resp struct {
+			DeletePost struct{ ID string }
+		}
+		c.MustPost(`mutation($id: ID!) { deletePost(id: $id) { id } }`, &resp, client.Var("id", posts[0].ID))
+		var count struct {
+			Posts struct{ Count int }
+		}
+		c.MustPost(`{ posts(first: 10) { count } }`, &count)
+		if count.Posts.Count != 2 {
+			t.Errorf("%d posts left, want 2", count.Posts.Count)
+		}
+	})
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,9 @@
+//go:build tools
+// +build tools
+
+package tools
+
+import (
+	_ "github.com/99designs/gqlgen"
+	_ "github.com/frankie-seb/sinatra/cmd/sinatra"
+)